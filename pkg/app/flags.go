@@ -35,6 +35,91 @@ var Flags = []cli.Flag{
 		Usage: "rpc node endpoint to connect to (specify multiple for high availability)",
 		Value: cli.NewStringSlice("http://localhost:26657"),
 	},
+	&cli.Float64Flag{
+		Name:  "node-rate-limit",
+		Usage: "maximum number of requests per second sent to each node (0 for unlimited)",
+	},
+	&cli.IntFlag{
+		Name:  "node-max-concurrency",
+		Usage: "maximum number of concurrent requests sent to each node (0 for unlimited)",
+	},
+	&cli.Int64Flag{
+		Name:  "node-max-lag",
+		Usage: "number of blocks a node can lag behind the highest synced node before being considered unhealthy (0 to disable)",
+		Value: 5,
+	},
+	&cli.DurationFlag{
+		Name:  "node-stuck-timeout",
+		Usage: "duration after which a node whose height stopped advancing while others progress is considered unhealthy (0 to disable)",
+		Value: time.Minute,
+	},
+	&cli.DurationFlag{
+		Name:  "sync-window",
+		Usage: "maximum age of the latest block of a synced node (0 to derive it from the average block time)",
+	},
+	&cli.BoolFlag{
+		Name:  "light-blocks",
+		Usage: "poll block commits & metas instead of full blocks (one commit per block and one meta per 20 blocks, full blocks are still fetched to read the vote extensions of tracked validators)",
+	},
+	&cli.DurationFlag{
+		Name:  "node-status-interval",
+		Usage: "interval between the polls of the status of each node",
+		Value: 30 * time.Second,
+	},
+	&cli.DurationFlag{
+		Name:  "node-blocks-interval",
+		Usage: "interval between the polls of the latest blocks of each node (postponed while blocks are received over websocket)",
+		Value: 10 * time.Second,
+	},
+	&cli.DurationFlag{
+		Name:  "node-info-interval",
+		Usage: "interval between the exports of the peers, mempool & versions of each node (mempool samples also detect empty proposals)",
+		Value: 30 * time.Second,
+	},
+	&cli.DurationFlag{
+		Name:  "node-health-interval",
+		Usage: "interval between the checks of the height lag of each node",
+		Value: 5 * time.Second,
+	},
+	&cli.IntFlag{
+		Name:  "query-attempts",
+		Usage: "maximum number of nodes to send a module query to before giving up",
+		Value: 3,
+	},
+	&cli.DurationFlag{
+		Name:  "validators-interval",
+		Usage: "interval between the fetches of the staking validators",
+		Value: 30 * time.Second,
+	},
+	&cli.DurationFlag{
+		Name:  "votes-interval",
+		Usage: "interval between the fetches of the proposals & votes",
+		Value: time.Minute,
+	},
+	&cli.DurationFlag{
+		Name:  "commissions-interval",
+		Usage: "interval between the fetches of the validators commission",
+		Value: time.Minute,
+	},
+	&cli.DurationFlag{
+		Name:  "upgrade-interval",
+		Usage: "interval between the fetches of the upgrade plan",
+		Value: time.Minute,
+	},
+	&cli.DurationFlag{
+		Name:  "validator-set-interval",
+		Usage: "interval between the syncs of the validator set on each node",
+		Value: time.Minute,
+	},
+	&cli.Float64Flag{
+		Name:  "interval-jitter",
+		Usage: "fraction by which each interval is randomly shortened or extended (eg. 0.1 for ±10%)",
+		Value: 0.1,
+	},
+	&cli.Int64Flag{
+		Name:  "every-blocks",
+		Usage: "fetch validators, votes, commissions & upgrade plan every N blocks instead of their interval (0 to disable)",
+	},
 	&cli.BoolFlag{
 		Name:  "no-gov",
 		Usage: "disable calls to gov module (useful for consumer chains)",
@@ -51,6 +136,11 @@ var Flags = []cli.Flag{
 		Name:  "no-upgrade",
 		Usage: "disable calls to upgrade module (for chains created without the upgrade module)",
 	},
+	&cli.IntFlag{
+		Name:  "proposal-window",
+		Usage: "number of blocks over which expected & actual proposed blocks are compared",
+		Value: 1000,
+	},
 	&cli.StringFlag{
 		Name:  "denom",
 		Usage: "denom used in metrics label (eg. atom or uatom)",
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -39,6 +40,13 @@ func WithMetrics(registry *prometheus.Registry) HTTPMuxOption {
 	}
 }
 
+// WithStatus serves the JSON encoded value returned by the given function on /status.
+func WithStatus(status func() interface{}) HTTPMuxOption {
+	return func(mux *http.ServeMux) {
+		mux.HandleFunc("/status", statusHandler(status))
+	}
+}
+
 func NewHTTPServer(addr string, options ...HTTPMuxOption) *HTTPServer {
 	mux := http.NewServeMux()
 	server := &HTTPServer{
@@ -82,3 +90,14 @@ func probeHandler(probe Probe) http.HandlerFunc {
 		}
 	}
 }
+
+func statusHandler(status func() interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(status()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
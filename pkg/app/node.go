@@ -0,0 +1,289 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/rpc"
+	"github.com/rs/zerolog/log"
+)
+
+// nodeConfig holds the per-node settings given as "__" query parameters of a
+// node endpoint (eg. http://localhost:26657?__websocket=0&__grpc=localhost:9090).
+type nodeConfig struct {
+	// RPC endpoint without the node settings
+	Endpoint string
+
+	DisableWebsocket bool
+	GRPCEndpoint     string
+	GRPCTLS          bool
+	RESTEndpoint     string
+
+	// Headers added to HTTP & websocket requests (eg. API keys)
+	Header http.Header
+
+	// TLS settings applied to the connections to the node (gRPC only if GRPCTLS is set)
+	TLSCAFile     string
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSServerName string
+	TLSInsecure   bool
+
+	tlsConfig *tls.Config
+
+	// Maximum requests per second and concurrent requests (0 for unlimited)
+	RateLimit      float64
+	MaxConcurrency int
+
+	// Poll commits & block metas instead of full blocks
+	LightBlocks bool
+
+	// Maximum age of the latest block of a synced node (0 for automatic)
+	SyncWindow time.Duration
+
+	// When to poll the status and latest blocks of the node
+	StatusSchedule rpc.Schedule
+	BlocksSchedule rpc.Schedule
+}
+
+// parseNodeConfig parses the settings of a node endpoint. Settings missing
+// from the endpoint are taken from the given defaults.
+func parseNodeConfig(endpoint string, defaults nodeConfig) (*nodeConfig, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse node endpoint: %w", err)
+	}
+
+	config := &nodeConfig{
+		Header:         http.Header{},
+		RateLimit:      defaults.RateLimit,
+		MaxConcurrency: defaults.MaxConcurrency,
+		SyncWindow:     defaults.SyncWindow,
+		LightBlocks:    defaults.LightBlocks,
+		StatusSchedule: defaults.StatusSchedule,
+		BlocksSchedule: defaults.BlocksSchedule,
+	}
+	query := u.Query()
+	for key, values := range query {
+		if !strings.HasPrefix(key, "__") {
+			continue
+		}
+		value := values[len(values)-1]
+
+		switch key {
+		case "__websocket":
+			config.DisableWebsocket = value == "0"
+		case "__grpc":
+			config.GRPCEndpoint = value
+		case "__grpc_tls":
+			config.GRPCTLS = value == "1"
+		case "__rest":
+			config.RESTEndpoint = value
+		case "__header":
+			for _, header := range values {
+				name, value, err := parseHeader(header)
+				if err != nil {
+					return nil, err
+				}
+				config.Header.Add(name, value)
+			}
+		case "__tls_ca":
+			config.TLSCAFile = value
+		case "__tls_cert":
+			config.TLSCertFile = value
+		case "__tls_key":
+			config.TLSKeyFile = value
+		case "__tls_server_name":
+			config.TLSServerName = value
+		case "__tls_insecure":
+			config.TLSInsecure = value == "1"
+		case "__rate_limit":
+			config.RateLimit, err = strconv.ParseFloat(value, 64)
+			if err != nil || config.RateLimit < 0 {
+				return nil, fmt.Errorf("invalid rate limit: %s", value)
+			}
+		case "__max_concurrency":
+			config.MaxConcurrency, err = strconv.Atoi(value)
+			if err != nil || config.MaxConcurrency < 0 {
+				return nil, fmt.Errorf("invalid max concurrency: %s", value)
+			}
+		case "__light_blocks":
+			config.LightBlocks = value == "1"
+		case "__sync_window":
+			config.SyncWindow, err = time.ParseDuration(value)
+			if err != nil || config.SyncWindow < 0 {
+				return nil, fmt.Errorf("invalid sync window: %s", value)
+			}
+		default:
+			// Unknown settings are dropped to keep older configs working
+			log.Warn().Msgf("ignoring unknown node setting: %s", key)
+		}
+		query.Del(key)
+	}
+	u.RawQuery = query.Encode()
+	config.Endpoint = u.String()
+
+	if config.GRPCEndpoint != "" && config.RESTEndpoint != "" {
+		return nil, fmt.Errorf("__grpc and __rest cannot be used together")
+	}
+
+	config.tlsConfig, err = config.loadTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// parseHeader parses a header given as "Name:value". The value can be read
+// from an environment variable (Name:env:VAR) or a file (Name:file:/path).
+func parseHeader(header string) (string, string, error) {
+	name, value, found := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return "", "", fmt.Errorf("invalid header %q (expected Name:value)", header)
+	}
+	value = strings.TrimSpace(value)
+
+	switch {
+	case strings.HasPrefix(value, "env:"):
+		variable := strings.TrimPrefix(value, "env:")
+		envValue, ok := os.LookupEnv(variable)
+		if !ok {
+			return "", "", fmt.Errorf("environment variable %s is not set for header %s", variable, name)
+		}
+		value = envValue
+	case strings.HasPrefix(value, "file:"):
+		content, err := os.ReadFile(strings.TrimPrefix(value, "file:"))
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read header %s: %w", name, err)
+		}
+		value = strings.TrimSpace(string(content))
+	}
+
+	return name, value, nil
+}
+
+// grpcTLSConfig returns the TLS config of the gRPC connection, nil unless
+// __grpc_tls is set (the gRPC endpoint may be plaintext even when the RPC
+// endpoint isn't). The TLS settings of the node apply when set.
+func (c *nodeConfig) grpcTLSConfig() *tls.Config {
+	if !c.GRPCTLS {
+		return nil
+	}
+	if c.tlsConfig != nil {
+		return c.tlsConfig
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+// loadTLSConfig returns the TLS config matching the TLS settings, or nil when
+// none is set to keep the default config.
+func (c *nodeConfig) loadTLSConfig() (*tls.Config, error) {
+	if c.TLSCAFile == "" && c.TLSCertFile == "" && c.TLSKeyFile == "" && c.TLSServerName == "" && !c.TLSInsecure {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.TLSServerName,
+		InsecureSkipVerify: c.TLSInsecure,
+	}
+
+	if c.TLSCAFile != "" {
+		ca, err := os.ReadFile(c.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls ca: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in tls ca %s", c.TLSCAFile)
+		}
+	}
+
+	if c.TLSCertFile != "" || c.TLSKeyFile != "" {
+		if c.TLSCertFile == "" || c.TLSKeyFile == "" {
+			return nil, fmt.Errorf("__tls_cert and __tls_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// HTTPClient returns the HTTP client used to send RPC calls to the node.
+func (c *nodeConfig) HTTPClient() (*http.Client, error) {
+	return rpc.NewHTTPClient(c.Endpoint, c.Header, c.tlsConfig)
+}
+
+// NodeOptions returns the rpc options matching the node settings.
+func (c *nodeConfig) NodeOptions() ([]rpc.NodeOption, error) {
+	opts := []rpc.NodeOption{}
+
+	if c.DisableWebsocket {
+		opts = append(opts, rpc.DisableWebsocket())
+	}
+
+	if c.RateLimit > 0 || c.MaxConcurrency > 0 {
+		opts = append(opts, rpc.RateLimit(c.RateLimit, c.MaxConcurrency))
+	}
+
+	if c.StatusSchedule.Interval > 0 {
+		opts = append(opts, rpc.StatusSchedule(c.StatusSchedule))
+	}
+
+	if c.BlocksSchedule.Interval > 0 {
+		opts = append(opts, rpc.BlocksSchedule(c.BlocksSchedule))
+	}
+
+	if c.LightBlocks {
+		opts = append(opts, rpc.LightBlocks())
+	}
+
+	if c.SyncWindow > 0 {
+		opts = append(opts, rpc.SyncWindow(c.SyncWindow))
+	}
+
+	if len(c.Header) > 0 {
+		opts = append(opts, rpc.WebsocketHeader(c.Header))
+	}
+
+	if c.tlsConfig != nil {
+		opts = append(opts, rpc.WebsocketTLS(c.tlsConfig))
+	}
+
+	if c.GRPCEndpoint != "" {
+		conn, err := rpc.DialGRPC(c.GRPCEndpoint, c.grpcTLSConfig())
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, rpc.QueryConn(conn))
+	}
+
+	if c.RESTEndpoint != "" {
+		client, err := rpc.NewHTTPClient(c.RESTEndpoint, c.Header, c.tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		client.Timeout = 30 * time.Second
+
+		conn, err := rpc.NewRESTConn(c.RESTEndpoint, client)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, rpc.QueryConn(conn))
+	}
+
+	return opts, nil
+}
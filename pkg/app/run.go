@@ -8,11 +8,10 @@ import (
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/cometbft/cometbft/rpc/client/http"
-	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
-	"github.com/cosmos/cosmos-sdk/types/query"
 	staking "github.com/cosmos/cosmos-sdk/x/staking/types"
 	"github.com/fatih/color"
 	_ "github.com/kilnfi/cosmos-validator-watcher/pkg/crypto"
@@ -25,6 +24,7 @@ import (
 	"github.com/samber/lo"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 )
 
 func RunFunc(cCtx *cli.Context) error {
@@ -38,10 +38,29 @@ func RunFunc(cCtx *cli.Context) error {
 		namespace           = cCtx.String("namespace")
 		noColor             = cCtx.Bool("no-color")
 		nodes               = cCtx.StringSlice("node")
+		nodeRateLimit       = cCtx.Float64("node-rate-limit")
+		nodeMaxConcurrency  = cCtx.Int("node-max-concurrency")
+		nodeMaxLag          = cCtx.Int64("node-max-lag")
+		nodeStuckTimeout    = cCtx.Duration("node-stuck-timeout")
+		syncWindow          = cCtx.Duration("sync-window")
+		lightBlocks         = cCtx.Bool("light-blocks")
+		nodeStatusInterval  = cCtx.Duration("node-status-interval")
+		nodeBlocksInterval  = cCtx.Duration("node-blocks-interval")
+		nodeInfoInterval    = cCtx.Duration("node-info-interval")
+		nodeHealthInterval  = cCtx.Duration("node-health-interval")
+		queryAttempts       = cCtx.Int("query-attempts")
+		validatorsInterval  = cCtx.Duration("validators-interval")
+		votesInterval       = cCtx.Duration("votes-interval")
+		commissionsInterval = cCtx.Duration("commissions-interval")
+		upgradeInterval     = cCtx.Duration("upgrade-interval")
+		valSetInterval      = cCtx.Duration("validator-set-interval")
+		intervalJitter      = cCtx.Float64("interval-jitter")
+		everyBlocks         = cCtx.Int64("every-blocks")
 		noGov               = cCtx.Bool("no-gov")
 		noStaking           = cCtx.Bool("no-staking")
 		noUpgrade           = cCtx.Bool("no-upgrade")
 		noCommission        = cCtx.Bool("no-commission")
+		proposalWindow      = cCtx.Int("proposal-window")
 		denom               = cCtx.String("denom")
 		denomExpon          = cCtx.Uint("denom-exponent")
 		startTimeout        = cCtx.Duration("start-timeout")
@@ -75,7 +94,19 @@ func RunFunc(cCtx *cli.Context) error {
 	defer cancel()
 
 	// Test connection to nodes
-	pool, err := createNodePool(startCtx, nodes)
+	nodeDefaults := nodeConfig{
+		RateLimit:      nodeRateLimit,
+		MaxConcurrency: nodeMaxConcurrency,
+		SyncWindow:     syncWindow,
+		LightBlocks:    lightBlocks,
+		StatusSchedule: rpc.Schedule{Interval: nodeStatusInterval, Jitter: intervalJitter},
+		BlocksSchedule: rpc.Schedule{Interval: nodeBlocksInterval, Jitter: intervalJitter},
+	}
+	pool, err := createNodePool(startCtx, nodes, nodeDefaults,
+		rpc.QueryAttempts(queryAttempts),
+		rpc.MaxLag(nodeMaxLag),
+		rpc.StuckTimeout(nodeStuckTimeout),
+	)
 	if err != nil {
 		return err
 	}
@@ -113,7 +144,15 @@ func RunFunc(cCtx *cli.Context) error {
 	//
 	metrics := metrics.New(namespace)
 	metrics.Register()
-	blockWatcher := watcher.NewBlockWatcher(trackedValidators, metrics, os.Stdout, wh, blockWebhooks)
+	// Schedule of the pool watchers, driven by blocks when requested
+	poolSchedule := func(interval time.Duration) rpc.Schedule {
+		return rpc.Schedule{Interval: interval, Jitter: intervalJitter, Blocks: everyBlocks}
+	}
+
+	blockWatcher := watcher.NewBlockWatcher(trackedValidators, metrics, os.Stdout, wh, blockWebhooks, watcher.BlockWatcherOptions{
+		ProposalWindow:       proposalWindow,
+		ValidatorSetSchedule: rpc.Schedule{Interval: valSetInterval, Jitter: intervalJitter},
+	})
 	errg.Go(func() error {
 		return blockWatcher.Start(ctx)
 	})
@@ -121,8 +160,18 @@ func RunFunc(cCtx *cli.Context) error {
 	errg.Go(func() error {
 		return statusWatcher.Start(ctx)
 	})
+	nodeWatcher := watcher.NewNodeWatcher(metrics, pool, watcher.NodeWatcherOptions{
+		InfoSchedule:   rpc.Schedule{Interval: nodeInfoInterval, Jitter: intervalJitter},
+		HealthSchedule: rpc.Schedule{Interval: nodeHealthInterval, Jitter: intervalJitter},
+	})
+	nodeWatcher.OnMempool(blockWatcher.OnNodeMempool)
+	errg.Go(func() error {
+		return nodeWatcher.Start(ctx)
+	})
 	if !noCommission {
-		commissionWatcher := watcher.NewCommissionsWatcher(trackedValidators, metrics, pool)
+		commissionWatcher := watcher.NewCommissionsWatcher(trackedValidators, metrics, pool, watcher.CommissionWatcherOptions{
+			Schedule: poolSchedule(commissionsInterval),
+		})
 		errg.Go(func() error {
 			return commissionWatcher.Start(ctx)
 		})
@@ -135,6 +184,7 @@ func RunFunc(cCtx *cli.Context) error {
 		validatorsWatcher := watcher.NewValidatorsWatcher(trackedValidators, metrics, pool, watcher.ValidatorsWatcherOptions{
 			Denom:         denom,
 			DenomExponent: denomExpon,
+			Schedule:      poolSchedule(validatorsInterval),
 		})
 		errg.Go(func() error {
 			return validatorsWatcher.Start(ctx)
@@ -147,6 +197,7 @@ func RunFunc(cCtx *cli.Context) error {
 	if !noGov {
 		votesWatcher := watcher.NewVotesWatcher(trackedValidators, metrics, pool, watcher.VotesWatcherOptions{
 			GovModuleVersion: xGov,
+			Schedule:         poolSchedule(votesInterval),
 		})
 		errg.Go(func() error {
 			return votesWatcher.Start(ctx)
@@ -158,6 +209,7 @@ func RunFunc(cCtx *cli.Context) error {
 		upgradeWatcher = watcher.NewUpgradeWatcher(metrics, pool, wh, watcher.UpgradeWatcherOptions{
 			CheckPendingProposals: !noGov,
 			GovModuleVersion:      xGov,
+			Schedule:              poolSchedule(upgradeInterval),
 		})
 		errg.Go(func() error {
 			return upgradeWatcher.Start(ctx)
@@ -168,13 +220,21 @@ func RunFunc(cCtx *cli.Context) error {
 	// Register watchers on nodes events
 	//
 	for _, node := range pool.Nodes {
+		node.OnCall(nodeWatcher.OnNodeCall)
+		node.OnStreaming(nodeWatcher.OnNodeStreaming)
+		node.OnThrottle(nodeWatcher.OnNodeThrottle)
 		node.OnStart(blockWatcher.OnNodeStart)
+		node.OnEvent(rpc.EventValidatorSetUpdates, blockWatcher.OnValidatorSetUpdates)
 		node.OnStatus(statusWatcher.OnNodeStatus)
-		node.OnEvent(rpc.EventNewBlock, blockWatcher.OnNewBlock)
+	}
 
-		if upgradeWatcher != nil {
-			node.OnEvent(rpc.EventNewBlock, upgradeWatcher.OnNewBlock)
-		}
+	//
+	// Register watchers on pool blocks (deduplicated across nodes)
+	//
+	pool.OnNodeBlock(blockWatcher.OnNodeBlock)
+	pool.OnBlock(blockWatcher.OnBlock)
+	if upgradeWatcher != nil {
+		pool.OnBlock(upgradeWatcher.OnBlock)
 	}
 
 	//
@@ -197,6 +257,9 @@ func RunFunc(cCtx *cli.Context) error {
 		WithReadyProbe(readyProbe),
 		WithLiveProbe(upProbe),
 		WithMetrics(metrics.Registry),
+		WithStatus(func() interface{} {
+			return newStatusReport(pool, trackedValidators)
+		}),
 	)
 	errg.Go(func() error {
 		return httpServer.Run()
@@ -240,21 +303,27 @@ func logLevelFromString(level string) zerolog.Level {
 	}
 }
 
-func createNodePool(ctx context.Context, nodes []string) (*rpc.Pool, error) {
+func createNodePool(ctx context.Context, nodes []string, nodeDefaults nodeConfig, poolOptions ...rpc.PoolOption) (*rpc.Pool, error) {
 	rpcNodes := make([]*rpc.Node, len(nodes))
 	for i, endpoint := range nodes {
-		client, err := http.New(endpoint, "/websocket")
+		config, err := parseNodeConfig(endpoint, nodeDefaults)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create client: %w", err)
+			return nil, err
 		}
 
-		opts := []rpc.NodeOption{}
+		httpClient, err := config.HTTPClient()
+		if err != nil {
+			return nil, err
+		}
 
-		// Check is query string websocket is present in the endpoint
-		if u, err := url.Parse(endpoint); err == nil {
-			if u.Query().Get("__websocket") == "0" {
-				opts = append(opts, rpc.DisableWebsocket())
-			}
+		client, err := http.NewWithClient(config.Endpoint, "/websocket", httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client: %w", err)
+		}
+
+		opts, err := config.NodeOptions()
+		if err != nil {
+			return nil, err
 		}
 
 		rpcNodes[i] = rpc.NewNode(client, opts...)
@@ -293,25 +362,20 @@ func createNodePool(ctx context.Context, nodes []string) (*rpc.Pool, error) {
 		return nil, fmt.Errorf("no nodes synced")
 	}
 
-	return rpc.NewPool(chainID, rpcNodes), nil
+	return rpc.NewPool(chainID, rpcNodes, poolOptions...), nil
 }
 
 func createTrackedValidators(ctx context.Context, pool *rpc.Pool, validators []string, noStaking bool) ([]watcher.TrackedValidator, error) {
 	var stakingValidators []staking.Validator
 	if !noStaking {
-		node := pool.GetSyncedNode()
-		clientCtx := (client.Context{}).WithClient(node.Client)
-		queryClient := staking.NewQueryClient(clientCtx)
-
-		resp, err := queryClient.Validators(ctx, &staking.QueryValidatorsRequest{
-			Pagination: &query.PageRequest{
-				Limit: 3000,
-			},
+		// All the pages are fetched from the same node
+		err := pool.WithNodeConn(ctx, "", func(conn grpc.ClientConnInterface) (err error) {
+			stakingValidators, err = watcher.FetchStakingValidators(ctx, conn)
+			return err
 		})
 		if err != nil {
 			return nil, err
 		}
-		stakingValidators = resp.Validators
 	}
 
 	trackedValidators := lo.Map(validators, func(v string, _ int) watcher.TrackedValidator {
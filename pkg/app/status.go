@@ -0,0 +1,81 @@
+package app
+
+import (
+	"time"
+
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/rpc"
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/watcher"
+)
+
+// statusReport is the diagnostic report served on /status.
+type statusReport struct {
+	ChainID    string            `json:"chain_id"`
+	BestHeight int64             `json:"best_height"`
+	Nodes      []nodeStatus      `json:"nodes"`
+	Watchers   map[string]string `json:"watchers"` // node last used by each pool watcher
+	Validators []validatorStatus `json:"validators"`
+}
+
+type nodeStatus struct {
+	Endpoint    string     `json:"endpoint"`
+	ChainID     string     `json:"chain_id"`
+	Height      int64      `json:"height"`
+	Lag         int64      `json:"lag"`
+	Synced      bool       `json:"synced"`
+	Healthy     bool       `json:"healthy"`
+	Streaming   bool       `json:"streaming"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+type validatorStatus struct {
+	Address         string `json:"address"`
+	Name            string `json:"name"`
+	Moniker         string `json:"moniker"`
+	OperatorAddress string `json:"operator_address"`
+}
+
+// newStatusReport returns the current state of the pool nodes & watchers.
+func newStatusReport(pool *rpc.Pool, validators []watcher.TrackedValidator) *statusReport {
+	report := &statusReport{
+		ChainID:    pool.ChainID,
+		BestHeight: pool.BestHeight(),
+		Nodes:      make([]nodeStatus, 0, len(pool.Nodes)),
+		Watchers:   make(map[string]string),
+		Validators: make([]validatorStatus, 0, len(validators)),
+	}
+
+	for _, node := range pool.Nodes {
+		status := nodeStatus{
+			Endpoint:  node.Redacted(),
+			ChainID:   node.ChainID(),
+			Height:    node.Height(),
+			Synced:    node.IsSynced(),
+			Healthy:   pool.IsHealthy(node),
+			Streaming: node.IsStreaming(),
+		}
+		if status.Height > 0 {
+			status.Lag = report.BestHeight - status.Height
+		}
+		if lastErrorAt, err := node.LastError(); err != nil {
+			status.LastError = err.Error()
+			status.LastErrorAt = &lastErrorAt
+		}
+		report.Nodes = append(report.Nodes, status)
+	}
+
+	for name, node := range pool.LastUsedNodes() {
+		report.Watchers[name] = node.Redacted()
+	}
+
+	for _, validator := range validators {
+		report.Validators = append(report.Validators, validatorStatus{
+			Address:         validator.Address,
+			Name:            validator.Name,
+			Moniker:         validator.Moniker,
+			OperatorAddress: validator.OperatorAddress,
+		})
+	}
+
+	return report
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/rpc"
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/watcher"
+	"gotest.tools/assert"
+)
+
+func TestStatusReport(t *testing.T) {
+	// Node answering all calls with an error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, err := rpchttp.New("http://user:secret@"+server.Listener.Addr().String(), "/websocket")
+	assert.NilError(t, err)
+	node := rpc.NewNode(client)
+	_, err = node.Client.Status(context.Background())
+	assert.Assert(t, err != nil)
+
+	pool := rpc.NewPool("chain-42", []*rpc.Node{node})
+	validators := []watcher.TrackedValidator{{
+		Address:         "3DC4DD610817606AD4A8F9D762A068A81E8741E2",
+		Name:            "kiln",
+		Moniker:         "Kiln",
+		OperatorAddress: "cosmosvaloper1uxlf7mvr8nep3gm7udf2u9remms2jyjqvwdul2",
+	}}
+
+	rec := httptest.NewRecorder()
+	statusHandler(func() interface{} {
+		return newStatusReport(pool, validators)
+	})(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var report statusReport
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "chain-42", report.ChainID)
+	assert.Equal(t, 1, len(report.Nodes))
+	assert.Equal(t, node.Redacted(), report.Nodes[0].Endpoint)
+	assert.Assert(t, !report.Nodes[0].Synced)
+	assert.Assert(t, !report.Nodes[0].Healthy)
+	assert.Assert(t, report.Nodes[0].LastError != "")
+	assert.Assert(t, report.Nodes[0].LastErrorAt != nil)
+	assert.Equal(t, 0, len(report.Watchers))
+	assert.DeepEqual(t, []validatorStatus{{
+		Address:         "3DC4DD610817606AD4A8F9D762A068A81E8741E2",
+		Name:            "kiln",
+		Moniker:         "Kiln",
+		OperatorAddress: "cosmosvaloper1uxlf7mvr8nep3gm7udf2u9remms2jyjqvwdul2",
+	}}, report.Validators)
+
+	// Credentials are never exposed
+	assert.Assert(t, !strings.Contains(rec.Body.String(), "secret"))
+}
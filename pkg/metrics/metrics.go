@@ -11,29 +11,54 @@ type Metrics struct {
 	// Global metrics
 	ActiveSet       *prometheus.GaugeVec
 	BlockHeight     *prometheus.GaugeVec
+	DroppedBlocks   *prometheus.CounterVec
 	ProposalEndTime *prometheus.GaugeVec
 	SeatPrice       *prometheus.GaugeVec
 	SkippedBlocks   *prometheus.CounterVec
 	TrackedBlocks   *prometheus.CounterVec
 	Transactions    *prometheus.CounterVec
 	UpgradePlan     *prometheus.GaugeVec
+	ValidatorSet    *prometheus.GaugeVec
 
 	// Validator metrics
-	Rank             		*prometheus.GaugeVec
-	ProposedBlocks   		*prometheus.CounterVec
-	ValidatedBlocks  		*prometheus.CounterVec
-	MissedBlocks     		*prometheus.CounterVec
-	SoloMissedBlocks 		*prometheus.CounterVec
+	Rank                    *prometheus.GaugeVec
+	ProposedBlocks          *prometheus.CounterVec
+	ValidatedBlocks         *prometheus.CounterVec
+	MissedBlocks            *prometheus.CounterVec
+	SoloMissedBlocks        *prometheus.CounterVec
 	ConsecutiveMissedBlocks *prometheus.GaugeVec
-	Tokens           		*prometheus.GaugeVec
-	IsBonded         		*prometheus.GaugeVec
-	IsJailed         		*prometheus.GaugeVec
-	Commission       		*prometheus.GaugeVec
-	Vote             		*prometheus.GaugeVec
+	PrecommitLatency        *prometheus.HistogramVec
+	Signatures              *prometheus.CounterVec
+	IncludedVoteExtensions  *prometheus.CounterVec
+	MissedVoteExtensions    *prometheus.CounterVec
+	ProposerPriority        *prometheus.GaugeVec
+	ExpectedProposedBlocks  *prometheus.GaugeVec
+	WindowProposedBlocks    *prometheus.GaugeVec
+	MissedProposals         *prometheus.CounterVec
+	EmptyProposedBlocks     *prometheus.CounterVec
+	LateProposedBlocks      *prometheus.CounterVec
+	Tokens                  *prometheus.GaugeVec
+	IsBonded                *prometheus.GaugeVec
+	IsJailed                *prometheus.GaugeVec
+	Commission              *prometheus.GaugeVec
+	Vote                    *prometheus.GaugeVec
 
 	// Node metrics
 	NodeBlockHeight *prometheus.GaugeVec
 	NodeSynced      *prometheus.GaugeVec
+	NodeHeightLag   *prometheus.GaugeVec
+	NodeHealthy     *prometheus.GaugeVec
+	NodeRPCLatency  *prometheus.HistogramVec
+	NodeRPCErrors   *prometheus.CounterVec
+	NodeStreaming   *prometheus.GaugeVec
+	NodeReconnects  *prometheus.CounterVec
+	NodeThrottled   *prometheus.CounterVec
+	NodeInfo        *prometheus.GaugeVec
+	NodePeers       *prometheus.GaugeVec
+	NodeMempoolTxs  *prometheus.GaugeVec
+	NodeMempoolSize *prometheus.GaugeVec
+	NodeVotingPower *prometheus.GaugeVec
+	NodeDivergences *prometheus.CounterVec
 }
 
 func New(namespace string) *Metrics {
@@ -55,6 +80,14 @@ func New(namespace string) *Metrics {
 			},
 			[]string{"chain_id"},
 		),
+		ValidatorSet: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "validator_set_size",
+				Help:      "Number of validators fetched per set (staking: all the staking validators, consensus: CometBFT validator set)",
+			},
+			[]string{"chain_id", "set"},
+		),
 		SeatPrice: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -111,6 +144,87 @@ func New(namespace string) *Metrics {
 			},
 			[]string{"chain_id", "address", "name"},
 		),
+		PrecommitLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "precommit_latency",
+				Help:      "Delay in seconds between the block time and the validator precommit signature",
+				Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 3, 5, 10, 30},
+			},
+			[]string{"chain_id", "address", "name"},
+		),
+		Signatures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "signatures",
+				Help:      "Number of signatures per validator split by flag: commit, nil or absent (for a bonded validator)",
+			},
+			[]string{"chain_id", "address", "name", "flag"},
+		),
+		IncludedVoteExtensions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "included_vote_extensions",
+				Help:      "Number of vote extensions included per validator (for a bonded validator on chains with vote extensions)",
+			},
+			[]string{"chain_id", "address", "name"},
+		),
+		MissedVoteExtensions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "missed_vote_extensions",
+				Help:      "Number of missing vote extensions per validator (for a bonded validator on chains with vote extensions)",
+			},
+			[]string{"chain_id", "address", "name"},
+		),
+		ProposerPriority: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "proposer_priority",
+				Help:      "Proposer priority of the validator in the validator set",
+			},
+			[]string{"chain_id", "address", "name"},
+		),
+		ExpectedProposedBlocks: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "expected_proposed_blocks",
+				Help:      "Expected number of proposed blocks per validator over the proposal window (based on voting power)",
+			},
+			[]string{"chain_id", "address", "name"},
+		),
+		WindowProposedBlocks: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "window_proposed_blocks",
+				Help:      "Number of proposed blocks per validator over the proposal window",
+			},
+			[]string{"chain_id", "address", "name"},
+		),
+		MissedProposals: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "missed_proposals",
+				Help:      "Number of missed proposals per validator (ie. block committed at round > 0 while the validator was the expected proposer)",
+			},
+			[]string{"chain_id", "address", "name"},
+		),
+		EmptyProposedBlocks: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "empty_proposed_blocks",
+				Help:      "Number of empty blocks proposed per validator while the mempool was not empty (approximated by the mempool size sampled by the node info polls since the previous block, unknown when no poll happened meanwhile)",
+			},
+			[]string{"chain_id", "address", "name"},
+		),
+		LateProposedBlocks: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "late_proposed_blocks",
+				Help:      "Number of proposed blocks per validator committed at round > 0",
+			},
+			[]string{"chain_id", "address", "name"},
+		),
 		TrackedBlocks: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
@@ -127,6 +241,14 @@ func New(namespace string) *Metrics {
 			},
 			[]string{"chain_id"},
 		),
+		DroppedBlocks: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "dropped_blocks",
+				Help:      "Number of new blocks dropped because the block queue was full (also counted as skipped blocks)",
+			},
+			[]string{"chain_id"},
+		),
 		SkippedBlocks: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
@@ -187,10 +309,115 @@ func New(namespace string) *Metrics {
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Name:      "node_synced",
-				Help:      "Set to 1 is the node is synced (ie. not catching-up)",
+				Help:      "Set to 1 is the node is synced (ie. not catching-up and latest block within the sync window)",
 			},
 			[]string{"chain_id", "node"},
 		),
+		NodeHeightLag: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_height_lag",
+				Help:      "Number of blocks each node is behind the highest synced node of the pool",
+			},
+			[]string{"chain_id", "node"},
+		),
+		NodeHealthy: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_healthy",
+				Help:      "Set to 1 if the node is used by the pool (ie. synced, not lagging and not stuck)",
+			},
+			[]string{"chain_id", "node"},
+		),
+		NodeRPCLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "node_rpc_latency",
+				Help:      "Duration in seconds of the RPC calls made to each node",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"chain_id", "node", "method"},
+		),
+		NodeRPCErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "node_rpc_errors",
+				Help:      "Number of failed RPC calls made to each node",
+			},
+			[]string{"chain_id", "node", "method"},
+		),
+		NodeStreaming: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_streaming",
+				Help:      "Set to 1 when the node streams events over websocket, 0 when it relies on polling",
+			},
+			[]string{"chain_id", "node"},
+		),
+		NodeReconnects: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "node_websocket_reconnects",
+				Help:      "Number of times the websocket of the node has been reconnected",
+			},
+			[]string{"chain_id", "node"},
+		),
+		NodeThrottled: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "node_throttled_requests",
+				Help:      "Number of requests delayed by the rate limit of each node",
+			},
+			[]string{"chain_id", "node", "method"},
+		),
+		NodeInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_info",
+				Help:      "Information about each node (versions & moniker), always set to 1",
+			},
+			[]string{"chain_id", "node", "version", "app_version", "moniker"},
+		),
+		NodePeers: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_peers",
+				Help:      "Number of peers of each node by direction (inbound or outbound)",
+			},
+			[]string{"chain_id", "node", "direction"},
+		),
+		NodeMempoolTxs: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_mempool_txs",
+				Help:      "Number of transactions in the mempool of each node",
+			},
+			[]string{"chain_id", "node"},
+		),
+		NodeMempoolSize: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_mempool_bytes",
+				Help:      "Size in bytes of the transactions in the mempool of each node",
+			},
+			[]string{"chain_id", "node"},
+		),
+		NodeVotingPower: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_voting_power",
+				Help:      "Voting power of the validator key of each node (0 for non-validator nodes)",
+			},
+			[]string{"chain_id", "node"},
+		),
+		NodeDivergences: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "node_divergences",
+				Help:      "Number of blocks for which the node reported a different hash (block, app or last results) than another node",
+			},
+			[]string{"chain_id", "node", "hash"},
+		),
 		UpgradePlan: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -217,14 +444,26 @@ func (m *Metrics) Register() {
 	m.Registry.MustRegister(collectors.NewGoCollector())
 
 	m.Registry.MustRegister(m.BlockHeight)
+	m.Registry.MustRegister(m.DroppedBlocks)
 	m.Registry.MustRegister(m.ActiveSet)
 	m.Registry.MustRegister(m.SeatPrice)
+	m.Registry.MustRegister(m.ValidatorSet)
 	m.Registry.MustRegister(m.Rank)
 	m.Registry.MustRegister(m.ProposedBlocks)
 	m.Registry.MustRegister(m.ValidatedBlocks)
 	m.Registry.MustRegister(m.MissedBlocks)
 	m.Registry.MustRegister(m.SoloMissedBlocks)
 	m.Registry.MustRegister(m.ConsecutiveMissedBlocks)
+	m.Registry.MustRegister(m.PrecommitLatency)
+	m.Registry.MustRegister(m.Signatures)
+	m.Registry.MustRegister(m.IncludedVoteExtensions)
+	m.Registry.MustRegister(m.MissedVoteExtensions)
+	m.Registry.MustRegister(m.ProposerPriority)
+	m.Registry.MustRegister(m.ExpectedProposedBlocks)
+	m.Registry.MustRegister(m.WindowProposedBlocks)
+	m.Registry.MustRegister(m.MissedProposals)
+	m.Registry.MustRegister(m.EmptyProposedBlocks)
+	m.Registry.MustRegister(m.LateProposedBlocks)
 	m.Registry.MustRegister(m.TrackedBlocks)
 	m.Registry.MustRegister(m.Transactions)
 	m.Registry.MustRegister(m.SkippedBlocks)
@@ -235,6 +474,19 @@ func (m *Metrics) Register() {
 	m.Registry.MustRegister(m.Vote)
 	m.Registry.MustRegister(m.NodeBlockHeight)
 	m.Registry.MustRegister(m.NodeSynced)
+	m.Registry.MustRegister(m.NodeHeightLag)
+	m.Registry.MustRegister(m.NodeHealthy)
+	m.Registry.MustRegister(m.NodeRPCLatency)
+	m.Registry.MustRegister(m.NodeRPCErrors)
+	m.Registry.MustRegister(m.NodeStreaming)
+	m.Registry.MustRegister(m.NodeReconnects)
+	m.Registry.MustRegister(m.NodeThrottled)
+	m.Registry.MustRegister(m.NodeInfo)
+	m.Registry.MustRegister(m.NodePeers)
+	m.Registry.MustRegister(m.NodeMempoolTxs)
+	m.Registry.MustRegister(m.NodeMempoolSize)
+	m.Registry.MustRegister(m.NodeVotingPower)
+	m.Registry.MustRegister(m.NodeDivergences)
 	m.Registry.MustRegister(m.UpgradePlan)
 	m.Registry.MustRegister(m.ProposalEndTime)
 }
@@ -0,0 +1,205 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cometbft/cometbft/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Data hash of a block without transactions
+var emptyDataHash = types.Txs{}.Hash()
+
+const (
+	// Max number of block metas returned by the blockchain endpoint
+	blockMetasPageSize = 20
+
+	// Number of heights for which the transaction counts of light blocks are kept
+	lightBlockTxsSize = 100
+)
+
+// IsLightBlock returns true when the block only contains its header and last
+// commit while its transactions were not fetched (see LightBlocks).
+func IsLightBlock(block *types.Block) bool {
+	return len(block.Txs) == 0 && !bytes.Equal(block.DataHash, emptyDataHash)
+}
+
+// fetchBlock returns the block at the given height (latest if nil). In light
+// mode, only the header and the last commit of the block are fetched.
+func (n *Node) fetchBlock(ctx context.Context, height *int64) (*types.Block, error) {
+	if !n.lightBlocks {
+		resp, err := n.Client.Block(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Block == nil {
+			return nil, fmt.Errorf("no block returned")
+		}
+		return resp.Block, nil
+	}
+
+	// The block metas include the headers and the transaction counts (the
+	// latest ones are returned when no height is given)
+	minHeight, maxHeight := int64(0), int64(0)
+	if height != nil {
+		minHeight, maxHeight = *height, *height
+	}
+	metasResp, err := n.Client.BlockchainInfo(ctx, minHeight, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+	if len(metasResp.BlockMetas) == 0 {
+		return nil, fmt.Errorf("no block meta returned")
+	}
+	for _, meta := range metasResp.BlockMetas {
+		n.lightBlockTxs.add(meta.Header.Height, meta.NumTxs)
+	}
+
+	block := &types.Block{
+		Header:     metasResp.BlockMetas[0].Header,
+		LastCommit: &types.Commit{},
+	}
+	if lastHeight := block.Height - 1; lastHeight > 0 {
+		commitResp, err := n.Client.Commit(ctx, &lastHeight)
+		if err != nil {
+			return nil, err
+		}
+		block.LastCommit = commitResp.Commit
+	}
+
+	return block, nil
+}
+
+// fetchBlocks returns the blocks between the given heights (included). Blocks
+// failing to be fetched are skipped. In light mode, a single commit is
+// fetched per block since it contains the header of the block as well as the
+// last commit of the next one, and the transaction counts are read from the
+// block metas by pages.
+func (n *Node) fetchBlocks(ctx context.Context, from, to int64) []*types.Block {
+	blocks := make([]*types.Block, 0, max(0, to-from+1))
+
+	if !n.lightBlocks {
+		for height := from; height <= to; height++ {
+			block, err := n.fetchBlock(ctx, &height)
+			if err != nil {
+				log.Error().Err(err).Msgf("failed to sync with block %d", height)
+				continue
+			}
+			blocks = append(blocks, block)
+		}
+		return blocks
+	}
+
+	n.fetchBlockMetas(ctx, from, to)
+
+	var lastCommit *types.Commit
+	for height := from; height <= to; height++ {
+		if lastCommit == nil {
+			lastCommit = &types.Commit{}
+			if lastHeight := height - 1; lastHeight > 0 {
+				commitResp, err := n.Client.Commit(ctx, &lastHeight)
+				if err != nil {
+					log.Error().Err(err).Msgf("failed to sync with commit %d", lastHeight)
+					lastCommit = nil
+					continue
+				}
+				lastCommit = commitResp.Commit
+			}
+		}
+
+		// The commit of the block is the last commit of the next one
+		commitResp, err := n.Client.Commit(ctx, &height)
+		if err != nil || commitResp.Header == nil {
+			log.Error().Err(err).Msgf("failed to sync with commit %d", height)
+			lastCommit = nil
+			continue
+		}
+		blocks = append(blocks, &types.Block{
+			Header:     *commitResp.Header,
+			LastCommit: lastCommit,
+		})
+		lastCommit = commitResp.Commit
+	}
+
+	return blocks
+}
+
+// fetchBlockMetas saves the transaction counts of the blocks between the given
+// heights (included), read from their block metas. Pages already known (eg.
+// along with the latest block) are skipped.
+func (n *Node) fetchBlockMetas(ctx context.Context, from, to int64) {
+	for maxHeight := to; maxHeight >= from; maxHeight -= blockMetasPageSize {
+		minHeight := max(from, maxHeight-blockMetasPageSize+1)
+		if n.lightBlockTxs.hasAll(minHeight, maxHeight) {
+			continue
+		}
+
+		resp, err := n.Client.BlockchainInfo(ctx, minHeight, maxHeight)
+		if err != nil {
+			log.Warn().Err(err).Msgf("failed to get block metas from %d to %d", minHeight, maxHeight)
+			continue
+		}
+		for _, meta := range resp.BlockMetas {
+			n.lightBlockTxs.add(meta.Header.Height, meta.NumTxs)
+		}
+	}
+}
+
+// NumTxs returns the number of transactions of a light block fetched by the
+// node, as read from its block meta (false if unknown).
+func (n *Node) NumTxs(height int64) (int, bool) {
+	return n.lightBlockTxs.get(height)
+}
+
+// lightBlockTxs keeps the transaction counts of the latest light blocks.
+type lightBlockTxs struct {
+	mu        sync.Mutex
+	maxHeight int64
+	txs       map[int64]int
+}
+
+func (c *lightBlockTxs) add(height int64, numTxs int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.txs == nil {
+		c.txs = make(map[int64]int)
+	}
+	if height <= c.maxHeight-lightBlockTxsSize {
+		return
+	}
+	c.txs[height] = numTxs
+
+	// Forget about old heights
+	if height > c.maxHeight {
+		c.maxHeight = height
+		for h := range c.txs {
+			if h <= c.maxHeight-lightBlockTxsSize {
+				delete(c.txs, h)
+			}
+		}
+	}
+}
+
+func (c *lightBlockTxs) get(height int64) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	numTxs, ok := c.txs[height]
+	return numTxs, ok
+}
+
+func (c *lightBlockTxs) hasAll(from, to int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for height := from; height <= to; height++ {
+		if _, ok := c.txs[height]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/cometbft/cometbft/crypto/tmhash"
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+	"gotest.tools/assert"
+)
+
+// newBlocksServer returns a test RPC server serving the headers, commits and
+// blocks of a chain at the given height, along with the number of calls per method.
+func newBlocksServer(t *testing.T, latestHeight int64) (*httptest.Server, map[string]int) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+
+	header := func(height int64) *types.Header {
+		return &types.Header{ChainID: "chain-42", Height: height, DataHash: tmhash.Sum([]byte("txs"))}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpctypes.RPCRequest
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var params struct {
+			Height    string `json:"height"`
+			MinHeight string `json:"minHeight"`
+			MaxHeight string `json:"maxHeight"`
+		}
+		assert.NilError(t, json.Unmarshal(req.Params, &params))
+		height := latestHeight
+		if params.Height != "" {
+			height, _ = strconv.ParseInt(params.Height, 10, 64)
+		}
+
+		mu.Lock()
+		calls[req.Method]++
+		mu.Unlock()
+
+		var result interface{}
+		switch req.Method {
+		case "blockchain":
+			// Block metas by descending heights, with as many transactions as the
+			// height modulo 3 (the latest 20 blocks when no height is given)
+			minHeight, _ := strconv.ParseInt(params.MinHeight, 10, 64)
+			maxHeight, _ := strconv.ParseInt(params.MaxHeight, 10, 64)
+			if maxHeight == 0 {
+				maxHeight = latestHeight
+			}
+			minHeight = max(minHeight, maxHeight-19, 1)
+
+			metas := []*types.BlockMeta{}
+			for h := maxHeight; h >= minHeight; h-- {
+				metas = append(metas, &types.BlockMeta{Header: *header(h), NumTxs: int(h % 3)})
+			}
+			result = &ctypes.ResultBlockchainInfo{LastHeight: latestHeight, BlockMetas: metas}
+		case "commit":
+			result = &ctypes.ResultCommit{SignedHeader: types.SignedHeader{
+				Header: header(height),
+				Commit: &types.Commit{Height: height},
+			}}
+		case "block":
+			result = &ctypes.ResultBlock{Block: &types.Block{
+				Header:     *header(height),
+				Data:       types.Data{Txs: types.Txs{[]byte("txs")}},
+				LastCommit: &types.Commit{Height: height - 1},
+			}}
+		}
+		assert.NilError(t, json.NewEncoder(w).Encode(rpctypes.NewRPCSuccessResponse(req.ID, result)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, calls
+}
+
+func TestFetchBlocks(t *testing.T) {
+	t.Run("Full Blocks", func(t *testing.T) {
+		server, calls := newBlocksServer(t, 10)
+		client, err := rpchttp.New(server.URL, "/websocket")
+		assert.NilError(t, err)
+		node := NewNode(client)
+
+		blocks := node.fetchBlocks(context.Background(), 5, 8)
+		assert.Equal(t, 4, len(blocks))
+		assert.Equal(t, 4, calls["block"])
+		assert.Equal(t, 0, calls["commit"])
+		assert.Assert(t, !IsLightBlock(blocks[0]))
+	})
+
+	t.Run("Light Blocks", func(t *testing.T) {
+		server, calls := newBlocksServer(t, 10)
+		client, err := rpchttp.New(server.URL, "/websocket")
+		assert.NilError(t, err)
+		node := NewNode(client, LightBlocks())
+
+		blocks := node.fetchBlocks(context.Background(), 5, 8)
+		assert.Equal(t, 4, len(blocks))
+		for i, block := range blocks {
+			assert.Equal(t, int64(5+i), block.Height)
+			assert.Equal(t, int64(4+i), block.LastCommit.Height)
+			assert.Assert(t, IsLightBlock(block))
+		}
+		// One commit per block plus the last commit of the first one, and a
+		// page of block metas for the transaction counts
+		assert.Equal(t, 5, calls["commit"])
+		assert.Equal(t, 1, calls["blockchain"])
+		assert.Equal(t, 0, calls["block"])
+		numTxs, ok := node.NumTxs(8)
+		assert.Assert(t, ok)
+		assert.Equal(t, 2, numTxs)
+
+		latest, err := node.fetchBlock(context.Background(), nil)
+		assert.NilError(t, err)
+		assert.Equal(t, int64(10), latest.Height)
+		assert.Equal(t, int64(9), latest.LastCommit.Height)
+		assert.Equal(t, 2, calls["blockchain"])
+		assert.Equal(t, 0, calls["header"])
+		assert.Equal(t, 0, calls["block"])
+		numTxs, ok = node.NumTxs(10)
+		assert.Assert(t, ok)
+		assert.Equal(t, 1, numTxs)
+
+		// The block metas known with the latest block are not fetched again
+		node.fetchBlocks(context.Background(), 6, 9)
+		assert.Equal(t, 2, calls["blockchain"])
+	})
+}
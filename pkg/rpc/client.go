@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cometbft/cometbft/rpc/client/http"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+)
+
+type onClientWait func(ctx context.Context, method string) (func(), error)
+type onClientCall func(ctx context.Context, method string, duration time.Duration, err error)
+
+// Client wraps the CometBFT RPC client to keep track of every call made to the
+// node, and to wait for the node rate limits before each call.
+type Client struct {
+	*http.HTTP
+
+	onWait onClientWait
+	onCall onClientCall
+}
+
+func newClient(client *http.HTTP, onWait onClientWait, onCall onClientCall) *Client {
+	return &Client{
+		HTTP:   client,
+		onWait: onWait,
+		onCall: onCall,
+	}
+}
+
+func observe[T any](ctx context.Context, c *Client, method string, call func() (T, error)) (T, error) {
+	if c.onWait != nil {
+		release, err := c.onWait(ctx, method)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		defer release()
+	}
+
+	start := time.Now()
+	res, err := call()
+	if c.onCall != nil {
+		c.onCall(ctx, method, time.Since(start), err)
+	}
+	return res, err
+}
+
+func (c *Client) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
+	return observe(ctx, c, "status", func() (*ctypes.ResultStatus, error) {
+		return c.HTTP.Status(ctx)
+	})
+}
+
+func (c *Client) ABCIInfo(ctx context.Context) (*ctypes.ResultABCIInfo, error) {
+	return observe(ctx, c, "abci_info", func() (*ctypes.ResultABCIInfo, error) {
+		return c.HTTP.ABCIInfo(ctx)
+	})
+}
+
+func (c *Client) ABCIQuery(ctx context.Context, path string, data bytes.HexBytes) (*ctypes.ResultABCIQuery, error) {
+	return c.ABCIQueryWithOptions(ctx, path, data, rpcclient.DefaultABCIQueryOptions)
+}
+
+func (c *Client) ABCIQueryWithOptions(ctx context.Context, path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	return observe(ctx, c, "abci_query", func() (*ctypes.ResultABCIQuery, error) {
+		return c.HTTP.ABCIQueryWithOptions(ctx, path, data, opts)
+	})
+}
+
+func (c *Client) NumUnconfirmedTxs(ctx context.Context) (*ctypes.ResultUnconfirmedTxs, error) {
+	return observe(ctx, c, "num_unconfirmed_txs", func() (*ctypes.ResultUnconfirmedTxs, error) {
+		return c.HTTP.NumUnconfirmedTxs(ctx)
+	})
+}
+
+func (c *Client) NetInfo(ctx context.Context) (*ctypes.ResultNetInfo, error) {
+	return observe(ctx, c, "net_info", func() (*ctypes.ResultNetInfo, error) {
+		return c.HTTP.NetInfo(ctx)
+	})
+}
+
+func (c *Client) ConsensusParams(ctx context.Context, height *int64) (*ctypes.ResultConsensusParams, error) {
+	return observe(ctx, c, "consensus_params", func() (*ctypes.ResultConsensusParams, error) {
+		return c.HTTP.ConsensusParams(ctx, height)
+	})
+}
+
+func (c *Client) BlockchainInfo(ctx context.Context, minHeight, maxHeight int64) (*ctypes.ResultBlockchainInfo, error) {
+	return observe(ctx, c, "blockchain", func() (*ctypes.ResultBlockchainInfo, error) {
+		return c.HTTP.BlockchainInfo(ctx, minHeight, maxHeight)
+	})
+}
+
+func (c *Client) Block(ctx context.Context, height *int64) (*ctypes.ResultBlock, error) {
+	return observe(ctx, c, "block", func() (*ctypes.ResultBlock, error) {
+		return c.HTTP.Block(ctx, height)
+	})
+}
+
+func (c *Client) Header(ctx context.Context, height *int64) (*ctypes.ResultHeader, error) {
+	return observe(ctx, c, "header", func() (*ctypes.ResultHeader, error) {
+		return c.HTTP.Header(ctx, height)
+	})
+}
+
+func (c *Client) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit, error) {
+	return observe(ctx, c, "commit", func() (*ctypes.ResultCommit, error) {
+		return c.HTTP.Commit(ctx, height)
+	})
+}
+
+func (c *Client) Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error) {
+	return observe(ctx, c, "validators", func() (*ctypes.ResultValidators, error) {
+		return c.HTTP.Validators(ctx, height, page, perPage)
+	})
+}
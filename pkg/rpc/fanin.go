@@ -0,0 +1,114 @@
+package rpc
+
+import (
+	"context"
+
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// OnPoolBlock is called by the pool with a block received from one of its nodes.
+type OnPoolBlock func(ctx context.Context, n *Node, block *types.Block)
+
+type nodeBlock struct {
+	node  *Node
+	block *types.Block
+}
+
+type blockKey struct {
+	height int64
+	hash   string
+}
+
+// blockFanIn merges the new blocks received from all the nodes of the pool
+// and dispatches them from a single goroutine, so that block callbacks never
+// run concurrently. Blocks are deduplicated by height & hash per node (the
+// same block can be both streamed and polled), so that blocks of a same
+// height with different hashes are still dispatched per node to detect
+// divergences. Across nodes, the first node delivering a height wins.
+type blockFanIn struct {
+	blocks      chan nodeBlock
+	onNodeBlock []OnPoolBlock
+	onBlock     []OnPoolBlock
+
+	// Only accessed by the dispatch goroutine
+	nodeBlocks map[*Node]blockKey
+	height     int64
+}
+
+func newBlockFanIn() *blockFanIn {
+	return &blockFanIn{
+		blocks:     make(chan nodeBlock, 100),
+		nodeBlocks: make(map[*Node]blockKey),
+	}
+}
+
+// OnNodeBlock registers a callback called once for each block of each node.
+// Callbacks are called from the dispatch goroutine and must not block: slow
+// work (eg. RPC calls) has to be handed off to another goroutine.
+func (p *Pool) OnNodeBlock(callback OnPoolBlock) {
+	p.fanIn.onNodeBlock = append(p.fanIn.onNodeBlock, callback)
+}
+
+// OnBlock registers a callback called once for each new block height across
+// all the nodes, with the node which delivered it first. Like OnNodeBlock,
+// callbacks must not block.
+func (p *Pool) OnBlock(callback OnPoolBlock) {
+	p.fanIn.onBlock = append(p.fanIn.onBlock, callback)
+}
+
+// receiveBlock is the new block event handler registered on each node.
+func (f *blockFanIn) receiveBlock(ctx context.Context, n *Node, evt *ctypes.ResultEvent) error {
+	// Ignore blocks if node is catching up
+	if !n.IsSynced() {
+		return nil
+	}
+
+	data, ok := evt.Data.(types.EventDataNewBlock)
+	if !ok || data.Block == nil {
+		return nil
+	}
+
+	select {
+	case f.blocks <- nodeBlock{node: n, block: data.Block}:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (f *blockFanIn) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case nb := <-f.blocks:
+			f.dispatch(ctx, nb.node, nb.block)
+		}
+	}
+}
+
+func (f *blockFanIn) dispatch(ctx context.Context, n *Node, block *types.Block) {
+	// Skip blocks already received from the same node
+	key := blockKey{height: block.Height, hash: block.Hash().String()}
+	if latest := f.nodeBlocks[n]; key.height < latest.height || key == latest {
+		return
+	}
+	f.nodeBlocks[n] = key
+
+	for _, onNodeBlock := range f.onNodeBlock {
+		onNodeBlock(ctx, n, block)
+	}
+
+	// Skip blocks already received from another node. Heights are not keyed
+	// by hash: a block of the same height with a different hash delivered
+	// later by another node is only seen by the OnNodeBlock callbacks (which
+	// detect divergences), never by the OnBlock ones.
+	if block.Height <= f.height {
+		return
+	}
+	f.height = block.Height
+
+	for _, onBlock := range f.onBlock {
+		onBlock(ctx, n, block)
+	}
+}
@@ -0,0 +1,58 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// DialGRPC creates a gRPC client connection to the given target (host:port)
+// to query the modules of a node. TLS is used when tlsConfig is not nil.
+func DialGRPC(target string, tlsConfig *tls.Config) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	// Cosmos SDK messages are gogoproto messages and need their own codec
+	grpcCodec := codec.NewProtoCodec(codectypes.NewInterfaceRegistry()).GRPCCodec()
+
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(grpcCodec)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc client for %s: %w", target, err)
+	}
+
+	return conn, nil
+}
+
+// observedConn wraps a gRPC client connection to keep track of every call
+// made to the node, and to wait for the node rate limits before each call.
+type observedConn struct {
+	grpc.ClientConnInterface
+
+	onWait onClientWait
+	onCall onClientCall
+}
+
+func (c *observedConn) Invoke(ctx context.Context, method string, req, reply interface{}, opts ...grpc.CallOption) error {
+	release, err := c.onWait(ctx, method)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	start := time.Now()
+	err = c.ClientConnInterface.Invoke(ctx, method, req, reply, opts...)
+	c.onCall(ctx, method, time.Since(start), err)
+	return err
+}
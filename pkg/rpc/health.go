@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// Weight of the latest call in the moving averages
+	healthAlpha = 0.1
+
+	// Weight of the latest block in the moving average of the block time
+	blockTimeAlpha = 0.1
+)
+
+// nodeHealth keeps moving averages of the error rate and latency of the calls
+// made to a node.
+type nodeHealth struct {
+	mu        sync.RWMutex
+	calls     int
+	errorRate float64
+	latency   float64 // seconds
+	lastErr   error
+	lastErrAt time.Time
+}
+
+func (h *nodeHealth) observe(duration time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	failure := 0.0
+	if err != nil {
+		failure = 1
+		h.lastErr = err
+		h.lastErrAt = time.Now()
+	}
+
+	if h.calls == 0 {
+		h.errorRate = failure
+		h.latency = duration.Seconds()
+	} else {
+		h.errorRate = healthAlpha*failure + (1-healthAlpha)*h.errorRate
+		h.latency = healthAlpha*duration.Seconds() + (1-healthAlpha)*h.latency
+	}
+	h.calls++
+}
+
+// ErrorRate returns the moving average of failed calls (between 0 and 1).
+func (h *nodeHealth) ErrorRate() float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.errorRate
+}
+
+// Latency returns the moving average of the calls duration.
+func (h *nodeHealth) Latency() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return time.Duration(h.latency * float64(time.Second))
+}
+
+// LastError returns when the latest call failed and its error.
+func (h *nodeHealth) LastError() (time.Time, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.lastErrAt, h.lastErr
+}
+
+// healthScore returns a score for a node given its error rate, latency and lag
+// behind the best known height (lower is healthier). Values are bucketed so
+// that nodes behaving similarly get the same score.
+func healthScore(errorRate float64, latency time.Duration, lag int64) int {
+	score := int(errorRate*10) * 10
+	score += int(latency / (250 * time.Millisecond))
+	if lag > 2 {
+		score += int(min(lag, 100))
+	}
+	return score
+}
+
+// blockTimeAverage keeps a moving average of the time between the blocks
+// seen on a node.
+type blockTimeAverage struct {
+	mu         sync.RWMutex
+	lastHeight int64
+	lastTime   time.Time
+	average    time.Duration
+}
+
+func (b *blockTimeAverage) observe(height int64, blockTime time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if height <= b.lastHeight || blockTime.IsZero() {
+		return
+	}
+
+	if b.lastHeight > 0 && blockTime.After(b.lastTime) {
+		blockDuration := blockTime.Sub(b.lastTime) / time.Duration(height-b.lastHeight)
+		if b.average == 0 {
+			b.average = blockDuration
+		} else {
+			b.average = time.Duration(blockTimeAlpha*float64(blockDuration) + (1-blockTimeAlpha)*float64(b.average))
+		}
+	}
+
+	b.lastHeight = height
+	b.lastTime = blockTime
+}
+
+// get returns the average block time, or 0 when not enough blocks were seen.
+func (b *blockTimeAverage) get() time.Duration {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.average
+}
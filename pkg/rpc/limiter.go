@@ -0,0 +1,99 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter caps the rate (token bucket) and the number of concurrent requests
+// sent to a node. A zero rate or concurrency means unlimited.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64 // requests per second
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	slots chan struct{}
+}
+
+func newLimiter(rate float64, concurrency int) *limiter {
+	l := &limiter{
+		rate: rate,
+		// Allow bursts of one second worth of requests
+		burst: max(1, rate),
+	}
+	l.tokens = l.burst
+	if concurrency > 0 {
+		l.slots = make(chan struct{}, concurrency)
+	}
+	return l
+}
+
+// wait blocks until the request is allowed to be sent. It returns a function
+// releasing the concurrency slot once the request is done and whether the
+// request has been throttled.
+func (l *limiter) wait(ctx context.Context) (func(), bool, error) {
+	throttled := false
+
+	if delay := l.reserve(); delay > 0 {
+		throttled = true
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.refund()
+			return nil, throttled, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if l.slots == nil {
+		return func() {}, throttled, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		throttled = true
+		select {
+		case <-ctx.Done():
+			return nil, throttled, ctx.Err()
+		case l.slots <- struct{}{}:
+		}
+	}
+
+	return func() { <-l.slots }, throttled, nil
+}
+
+// reserve takes a token from the bucket and returns how long to wait before
+// it is actually available.
+func (l *limiter) reserve() time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// refund gives back a token reserved by a request which hasn't been sent.
+func (l *limiter) refund() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.burst, l.tokens+1)
+}
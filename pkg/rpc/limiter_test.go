@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Unlimited", func(t *testing.T) {
+		l := newLimiter(0, 0)
+		for i := 0; i < 100; i++ {
+			release, throttled, err := l.wait(ctx)
+			assert.NilError(t, err)
+			assert.Equal(t, false, throttled)
+			release()
+		}
+	})
+
+	t.Run("Rate", func(t *testing.T) {
+		l := newLimiter(20, 0)
+
+		// The burst is allowed right away
+		for i := 0; i < 20; i++ {
+			_, throttled, err := l.wait(ctx)
+			assert.NilError(t, err)
+			assert.Equal(t, false, throttled)
+		}
+
+		// Next request waits for a new token (~50ms)
+		start := time.Now()
+		_, throttled, err := l.wait(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, true, throttled)
+		assert.Assert(t, time.Since(start) >= 30*time.Millisecond)
+	})
+
+	t.Run("Refund Cancelled Requests", func(t *testing.T) {
+		l := newLimiter(1, 0)
+
+		_, _, err := l.wait(ctx)
+		assert.NilError(t, err)
+
+		// The request is cancelled before its token is available...
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, throttled, err := l.wait(timeoutCtx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, true, throttled)
+
+		// ...so the next one doesn't wait for it (~1s) on top of its own
+		assert.Assert(t, l.reserve() < 1100*time.Millisecond)
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		l := newLimiter(0, 1)
+
+		release, throttled, err := l.wait(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, false, throttled)
+
+		// No slot left until the first request is released
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, throttled, err = l.wait(timeoutCtx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, true, throttled)
+
+		release()
+		release, throttled, err = l.wait(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, false, throttled)
+		release()
+	})
+}
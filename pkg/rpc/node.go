@@ -2,17 +2,22 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/avast/retry-go/v4"
-	"github.com/cometbft/cometbft/rpc/client/http"
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 	"github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -24,9 +29,34 @@ const (
 	EventVote                = "Vote"
 )
 
+const (
+	// Delay between reconnection attempts of the websocket (doubled after each failure)
+	wsMinBackoff = 1 * time.Second
+	wsMaxBackoff = 1 * time.Minute
+
+	// The subscription is considered dead when no event is received for this
+	// duration or when polling finds blocks missed by the stream several times
+	wsStreamTimeout   = 2 * time.Minute
+	wsMaxMissedBlocks = 3
+
+	// The latest block of a synced node must be more recent than the sync
+	// window. Unless configured, the window spans a number of blocks based on
+	// the average block time observed on the node.
+	defaultSyncWindow = 120 * time.Second
+	minSyncWindow     = 30 * time.Second
+	syncWindowBlocks  = 20
+
+	// Default delays between the polls of the status and latest blocks
+	defaultStatusInterval = 30 * time.Second
+	defaultBlocksInterval = 10 * time.Second
+)
+
+type OnNodeCall func(ctx context.Context, n *Node, method string, duration time.Duration, err error)
 type OnNodeEvent func(ctx context.Context, n *Node, event *ctypes.ResultEvent) error
 type OnNodeStart func(ctx context.Context, n *Node) error
 type OnNodeStatus func(ctx context.Context, n *Node, status *ctypes.ResultStatus) error
+type OnNodeStreaming func(ctx context.Context, n *Node, streaming bool)
+type OnNodeThrottle func(ctx context.Context, n *Node, method string)
 
 type NodeOption func(*Node)
 
@@ -36,41 +66,134 @@ func DisableWebsocket() NodeOption {
 	}
 }
 
+// QueryConn sets the connection used to query the modules of the node (eg.
+// native gRPC or REST API) instead of ABCI queries.
+func QueryConn(conn grpc.ClientConnInterface) NodeOption {
+	return func(n *Node) {
+		n.queryConn = conn
+	}
+}
+
+// WebsocketHeader sets the headers added to the websocket handshake request.
+func WebsocketHeader(header http.Header) NodeOption {
+	return func(n *Node) {
+		n.wsHeader = header
+	}
+}
+
+// WebsocketTLS sets the TLS config used to connect the websocket.
+func WebsocketTLS(tlsConfig *tls.Config) NodeOption {
+	return func(n *Node) {
+		n.wsTLSConfig = tlsConfig
+	}
+}
+
+// SyncWindow sets the maximum age of the latest block of a synced node (0 to
+// derive it from the average block time).
+func SyncWindow(window time.Duration) NodeOption {
+	return func(n *Node) {
+		n.syncWindow = window
+	}
+}
+
+// StatusSchedule sets when the status of the node is polled (only the
+// interval and jitter of the schedule are used).
+func StatusSchedule(schedule Schedule) NodeOption {
+	return func(n *Node) {
+		n.statusSchedule = schedule
+	}
+}
+
+// BlocksSchedule sets when the latest blocks of the node are polled (only the
+// interval and jitter of the schedule are used). Polling is postponed each
+// time a block is received over the websocket.
+func BlocksSchedule(schedule Schedule) NodeOption {
+	return func(n *Node) {
+		n.blocksSchedule = schedule
+	}
+}
+
+// LightBlocks makes the node poll the commits and block metas (headers &
+// transaction counts) instead of the full blocks including all their
+// transactions.
+func LightBlocks() NodeOption {
+	return func(n *Node) {
+		n.lightBlocks = true
+	}
+}
+
+// RateLimit caps the number of requests per second and the number of
+// concurrent requests sent to the node (0 for unlimited).
+func RateLimit(rate float64, concurrency int) NodeOption {
+	return func(n *Node) {
+		n.limiter = newLimiter(rate, concurrency)
+	}
+}
+
 type Node struct {
-	Client *http.HTTP
+	// CometBFT RPC client (embedded) whose calls are rate limited & observed
+	Client *Client
 
 	// Save endpoint url for redacted logging
 	endpoint *url.URL
 
 	disableWebsocket bool
-
-	onStart  []OnNodeStart
-	onStatus []OnNodeStatus
-	onEvent  map[string][]OnNodeEvent
-
-	chainID       string
-	status        atomic.Value
-	latestBlock   atomic.Value
-	started       chan struct{}
-	startedOnce   sync.Once
-	subscriptions map[string]<-chan ctypes.ResultEvent
+	wsHeader         http.Header
+	wsTLSConfig      *tls.Config
+	queryConn        grpc.ClientConnInterface
+	syncWindow       time.Duration
+	lightBlocks      bool
+	statusSchedule   Schedule
+	blocksSchedule   Schedule
+
+	onCall      []OnNodeCall
+	onStart     []OnNodeStart
+	onStatus    []OnNodeStatus
+	onEvent     map[string][]OnNodeEvent
+	onStreaming []OnNodeStreaming
+	onThrottle  []OnNodeThrottle
+
+	chainID     string
+	status      atomic.Value
+	latestBlock atomic.Value
+	started     chan struct{}
+	startedOnce sync.Once
+	running     atomic.Bool
+	streaming   atomic.Bool
+	health      nodeHealth
+	limiter     *limiter
+
+	// Transaction counts of the polled light blocks
+	lightBlockTxs lightBlockTxs
+
+	// Connected websocket (nil while polling) and subscribed channels per query
+	streamMu      sync.Mutex
+	stream        *eventStream
+	subscriptions map[string]chan ctypes.ResultEvent
+
+	// Highest height seen and when it was first seen (unix nano)
+	trackedHeight    atomic.Int64
+	heightAdvancedAt atomic.Int64
+	blockTime        blockTimeAverage
 }
 
-func NewNode(client *http.HTTP, options ...NodeOption) *Node {
+func NewNode(client *rpchttp.HTTP, options ...NodeOption) *Node {
 	endpoint, _ := url.Parse(client.Remote())
 
 	node := &Node{
-		Client:        client,
 		endpoint:      endpoint,
 		started:       make(chan struct{}),
 		startedOnce:   sync.Once{},
-		subscriptions: make(map[string]<-chan ctypes.ResultEvent),
 		onEvent:       make(map[string][]OnNodeEvent),
+		subscriptions: make(map[string]chan ctypes.ResultEvent),
 	}
+	node.Client = newClient(client, node.wait, node.handleCall)
 
 	for _, opt := range options {
 		opt(node)
 	}
+	node.statusSchedule = node.statusSchedule.WithDefaultInterval(defaultStatusInterval)
+	node.blocksSchedule = node.blocksSchedule.WithDefaultInterval(defaultBlocksInterval)
 
 	return node
 }
@@ -93,6 +216,10 @@ func (n *Node) Redacted() string {
 	return n.endpoint.Redacted()
 }
 
+func (n *Node) OnCall(callback OnNodeCall) {
+	n.onCall = append(n.onCall, callback)
+}
+
 func (n *Node) OnStart(callback OnNodeStart) {
 	n.onStart = append(n.onStart, callback)
 }
@@ -105,33 +232,130 @@ func (n *Node) OnEvent(eventType string, callback OnNodeEvent) {
 	n.onEvent[eventType] = append(n.onEvent[eventType], callback)
 }
 
+// OnStreaming registers a callback called when the node starts or stops
+// streaming events over the websocket.
+func (n *Node) OnStreaming(callback OnNodeStreaming) {
+	n.onStreaming = append(n.onStreaming, callback)
+}
+
+// OnThrottle registers a callback called when a request to the node is
+// delayed by the rate limiter.
+func (n *Node) OnThrottle(callback OnNodeThrottle) {
+	n.onThrottle = append(n.onThrottle, callback)
+}
+
 func (n *Node) Started() chan struct{} {
 	return n.started
 }
 
+// IsRunning returns true once the node is ready and until its start loop
+// exits (see Start).
 func (n *Node) IsRunning() bool {
-	return n.Client.IsRunning()
+	return n.running.Load()
 }
 
+// IsStreaming returns true when events are received over the websocket,
+// false when the node relies on polling only.
+func (n *Node) IsStreaming() bool {
+	return n.streaming.Load()
+}
+
+// IsSynced returns true when the node isn't catching up and its latest block
+// is within the sync window.
 func (n *Node) IsSynced() bool {
 	status := n.loadStatus()
 	if status == nil {
 		return false
 	}
 
+	latestBlockTime := status.SyncInfo.LatestBlockTime
+	if block := n.getLatestBlock(); block != nil && block.Time.After(latestBlockTime) {
+		latestBlockTime = block.Time
+	}
+
 	return !status.SyncInfo.CatchingUp &&
-		status.SyncInfo.LatestBlockTime.After(time.Now().Add(-120*time.Second))
+		latestBlockTime.After(time.Now().Add(-n.SyncWindow()))
+}
+
+// SyncWindow returns the maximum age of the latest block of a synced node.
+func (n *Node) SyncWindow() time.Duration {
+	if n.syncWindow > 0 {
+		return n.syncWindow
+	}
+
+	average := n.blockTime.get()
+	if average == 0 {
+		return defaultSyncWindow
+	}
+	return max(minSyncWindow, average*syncWindowBlocks)
+}
+
+// AverageBlockTime returns the average time between blocks observed on the
+// node (0 until enough blocks are seen).
+func (n *Node) AverageBlockTime() time.Duration {
+	return n.blockTime.get()
+}
+
+// LastError returns when the latest failed call to the node happened and its
+// error (nil when no call failed).
+func (n *Node) LastError() (time.Time, error) {
+	return n.health.LastError()
+}
+
+// Height returns the latest known block height of the node.
+func (n *Node) Height() int64 {
+	height := int64(0)
+	if status := n.loadStatus(); status != nil {
+		height = status.SyncInfo.LatestBlockHeight
+	}
+	if block := n.getLatestBlock(); block != nil && block.Height > height {
+		height = block.Height
+	}
+	return height
+}
+
+// StalledFor returns for how long the height of the node hasn't advanced.
+func (n *Node) StalledFor() time.Duration {
+	advancedAt := n.heightAdvancedAt.Load()
+	if advancedAt == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, advancedAt))
+}
+
+func (n *Node) trackHeight(height int64, blockTime time.Time) {
+	n.blockTime.observe(height, blockTime)
+
+	for {
+		tracked := n.trackedHeight.Load()
+		if height <= tracked {
+			return
+		}
+		if n.trackedHeight.CompareAndSwap(tracked, height) {
+			n.heightAdvancedAt.Store(time.Now().UnixNano())
+			return
+		}
+	}
 }
 
 func (n *Node) ChainID() string {
 	return n.chainID
 }
 
+// Conn returns a gRPC client connection to query modules of the node, either
+// the configured query connection (gRPC or REST) or ABCI queries over RPC.
+func (n *Node) Conn() grpc.ClientConnInterface {
+	if n.queryConn != nil {
+		return &observedConn{ClientConnInterface: n.queryConn, onWait: n.wait, onCall: n.handleCall}
+	}
+	return client.Context{}.WithClient(n.Client)
+}
+
 func (n *Node) Start(ctx context.Context) error {
 	log := log.With().Str("node", n.Redacted()).Logger()
 
 	// Wait for the node to be ready
-	initTicker := time.NewTicker(30 * time.Second)
+	initTicker := time.NewTicker(n.statusSchedule.Interval)
 	for {
 		status, err := n.syncStatus(ctx)
 		if err != nil {
@@ -150,59 +374,214 @@ func (n *Node) Start(ctx context.Context) error {
 	}
 	initTicker.Stop()
 
-	// Start the websocket process
-	if !n.disableWebsocket {
-		if err := n.Client.Start(); err != nil {
-			return fmt.Errorf("failed to start client: %w", err)
-		}
-	}
-
-	blocksEvents, err := n.Subscribe(ctx, EventNewBlock)
-	if err != nil {
-		return fmt.Errorf("failed to get subscription: %w", err)
-	}
-
-	// Subscribe to validator set changes
-	validatorEvents, err := n.Subscribe(ctx, EventValidatorSetUpdates)
-	if err != nil {
-		return fmt.Errorf("failed to subscribe to events: %w", err)
-	}
-
 	// Call the start callbacks
 	n.startedOnce.Do(func() {
 		n.handleStart(ctx)
 	})
 
+	n.running.Store(true)
+	defer n.running.Store(false)
+
+	var (
+		stream       *eventStream
+		events       <-chan ctypes.ResultEvent
+		streamDone   <-chan struct{}
+		lastEvent    time.Time
+		missedBlocks int
+		backoff      = wsMinBackoff
+	)
+	defer func() {
+		if stream != nil {
+			n.setStream(nil)
+			stream.Close()
+		}
+	}()
+
+	// Connect the websocket right away, unless disabled (a nil channel is
+	// never selected)
+	var (
+		reconnectTimer *time.Timer
+		reconnect      <-chan time.Time
+	)
+	if !n.disableWebsocket {
+		reconnectTimer = time.NewTimer(0)
+		defer reconnectTimer.Stop()
+		reconnect = reconnectTimer.C
+	}
+
 	// Start the status loop
-	statusTicker := time.NewTicker(30 * time.Second)
-	blocksTicker := time.NewTicker(10 * time.Second)
+	statusTimer := time.NewTimer(n.statusSchedule.Next())
+	defer statusTimer.Stop()
+	blocksDelay := n.blocksSchedule.Next()
+	if n.disableWebsocket {
+		// Fetch the latest block right away (done on connection otherwise)
+		blocksDelay = 0
+	}
+	blocksTimer := time.NewTimer(blocksDelay)
+	defer blocksTimer.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			log.Debug().Err(ctx.Err()).Msgf("stopping node status loop")
+			n.setStreaming(ctx, false)
 			return nil
 
-		case evt := <-blocksEvents:
-			log.Debug().Msg("got new block event")
-			n.saveLatestBlock(evt.Data.(types.EventDataNewBlock).Block)
-			n.handleEvent(ctx, EventNewBlock, &evt)
-			blocksTicker.Reset(10 * time.Second)
-
-		case evt := <-validatorEvents:
-			log.Debug().Msg("got validator set update event")
-			n.handleEvent(ctx, EventValidatorSetUpdates, &evt)
-
-		case <-blocksTicker.C:
-			log.Debug().Msg("syncing latest blocks")
+		case <-reconnect:
+			log.Debug().Msg("connecting websocket")
+			s, err := dialEventStream(ctx, n.Client.Remote(), n.wsHeader, n.wsTLSConfig, n.streamQueries())
+			if err == nil {
+				if err = n.setStream(s); err != nil {
+					s.Close()
+				}
+			}
+			if err != nil {
+				log.Warn().Err(err).Msgf("failed to connect websocket, retrying in %s", backoff)
+				reconnectTimer.Reset(backoff)
+				backoff = min(backoff*2, wsMaxBackoff)
+				continue
+			}
+			stream, events, streamDone = s, s.Events(), s.Done()
+			lastEvent, missedBlocks = time.Now(), 0
+			n.setStreaming(ctx, true)
+
+			// Catch up with the blocks missed while disconnected
 			n.syncBlocks(ctx)
 
-		case <-statusTicker.C:
+		case <-streamDone:
+			log.Warn().Err(stream.Err()).Msgf("websocket disconnected, reconnecting in %s", backoff)
+			n.setStream(nil)
+			stream, events, streamDone = nil, nil, nil
+			n.setStreaming(ctx, false)
+			reconnectTimer.Reset(backoff)
+			backoff = min(backoff*2, wsMaxBackoff)
+
+		case evt := <-events:
+			lastEvent, missedBlocks = time.Now(), 0
+			backoff = wsMinBackoff
+
+			switch data := evt.Data.(type) {
+			case types.EventDataNewBlock:
+				log.Debug().Msg("got new block event")
+				n.saveLatestBlock(data.Block)
+				n.handleEvent(ctx, EventNewBlock, &evt)
+				resetTimer(blocksTimer, n.blocksSchedule.Next())
+			case types.EventDataValidatorSetUpdates:
+				log.Debug().Msg("got validator set update event")
+				n.handleEvent(ctx, EventValidatorSetUpdates, &evt)
+			}
+			n.publish(evt)
+
+		case <-blocksTimer.C:
+			blocksTimer.Reset(n.blocksSchedule.Next())
+			log.Debug().Msg("syncing latest blocks")
+			if n.syncBlocks(ctx) && stream != nil {
+				missedBlocks++
+			}
+
+			// Drop the connection when the subscription went quiet
+			if stream != nil && (missedBlocks >= wsMaxMissedBlocks || time.Since(lastEvent) > wsStreamTimeout) {
+				log.Warn().Msgf("websocket subscription is not receiving events (last event %s ago)", time.Since(lastEvent).Round(time.Second))
+				stream.Close()
+			}
+
+		case <-statusTimer.C:
+			statusTimer.Reset(n.statusSchedule.Next())
 			log.Debug().Msg("syncing status")
 			n.syncStatus(ctx)
 		}
 	}
 }
 
+// Subscribe returns a channel receiving the events of the given type streamed
+// over the websocket. Subscriptions are renewed on each reconnection. The
+// channel never receives any event when the websocket is disabled.
+func (n *Node) Subscribe(ctx context.Context, eventType string) (<-chan ctypes.ResultEvent, error) {
+	if n.disableWebsocket {
+		return make(chan ctypes.ResultEvent), nil
+	}
+
+	n.streamMu.Lock()
+	defer n.streamMu.Unlock()
+
+	query := eventQuery(eventType)
+	if out, ok := n.subscriptions[query]; ok {
+		return out, nil
+	}
+
+	// Subscribe on the current connection, next ones subscribe when connecting
+	if n.stream != nil {
+		if err := n.stream.Subscribe(query); err != nil {
+			return nil, fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+		}
+	}
+
+	n.subscriptions[query] = make(chan ctypes.ResultEvent, 10)
+
+	return n.subscriptions[query], nil
+}
+
+// streamQueries returns the queries to subscribe to when connecting the
+// websocket: the events handled by the node and the subscribed ones.
+func (n *Node) streamQueries() []string {
+	n.streamMu.Lock()
+	defer n.streamMu.Unlock()
+
+	queries := []string{
+		eventQuery(EventNewBlock),
+		eventQuery(EventValidatorSetUpdates),
+	}
+	for query := range n.subscriptions {
+		if query != queries[0] && query != queries[1] {
+			queries = append(queries, query)
+		}
+	}
+	return queries
+}
+
+// setStream saves the connected websocket (nil once disconnected), and
+// subscribes to the queries subscribed while it was connecting.
+func (n *Node) setStream(stream *eventStream) error {
+	n.streamMu.Lock()
+	defer n.streamMu.Unlock()
+
+	n.stream = stream
+	if stream == nil {
+		return nil
+	}
+
+	subscribed := make(map[string]bool)
+	for _, query := range stream.queries {
+		subscribed[query] = true
+	}
+	for query := range n.subscriptions {
+		if subscribed[query] {
+			continue
+		}
+		if err := stream.Subscribe(query); err != nil {
+			n.stream = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// publish forwards a streamed event to the channel subscribed to its query.
+// Like the CometBFT client, the event is dropped when the channel is full.
+func (n *Node) publish(evt ctypes.ResultEvent) {
+	n.streamMu.Lock()
+	out, ok := n.subscriptions[evt.Query]
+	n.streamMu.Unlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case out <- evt:
+	default:
+		log.Warn().Str("node", n.Redacted()).Msgf("subscription channel is full, dropping %s event", evt.Query)
+	}
+}
+
 func (n *Node) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
 	status := n.loadStatus()
 
@@ -236,6 +615,9 @@ func (n *Node) syncStatus(ctx context.Context) (*ctypes.ResultStatus, error) {
 	}, retryOpts...)
 
 	n.status.Store(status)
+	if status != nil {
+		n.trackHeight(status.SyncInfo.LatestBlockHeight, status.SyncInfo.LatestBlockTime)
+	}
 
 	if err != nil {
 		return status, fmt.Errorf("failed to get status of %s: %w", n.Redacted(), err)
@@ -270,6 +652,29 @@ func (n *Node) handleStart(ctx context.Context) {
 	close(n.started)
 }
 
+// wait blocks until the request is allowed by the rate limiter of the node.
+func (n *Node) wait(ctx context.Context, method string) (func(), error) {
+	if n.limiter == nil {
+		return func() {}, nil
+	}
+
+	release, throttled, err := n.limiter.wait(ctx)
+	if throttled {
+		for _, onThrottle := range n.onThrottle {
+			onThrottle(ctx, n, method)
+		}
+	}
+	return release, err
+}
+
+func (n *Node) handleCall(ctx context.Context, method string, duration time.Duration, err error) {
+	n.health.observe(duration, err)
+
+	for _, onCall := range n.onCall {
+		onCall(ctx, n, method, duration, err)
+	}
+}
+
 func (n *Node) handleEvent(ctx context.Context, eventType string, event *ctypes.ResultEvent) {
 	for _, onEvent := range n.onEvent[eventType] {
 		if err := onEvent(ctx, n, event); err != nil {
@@ -288,20 +693,17 @@ func (n *Node) getLatestBlock() *types.Block {
 
 func (n *Node) saveLatestBlock(block *types.Block) {
 	n.latestBlock.Store(block)
+	n.trackHeight(block.Height, block.Time)
 }
 
-func (n *Node) syncBlocks(ctx context.Context) {
+// syncBlocks polls the latest blocks of the node and returns true when new
+// blocks were found since the latest known block.
+func (n *Node) syncBlocks(ctx context.Context) bool {
 	// Fetch latest block
-	currentBlockResp, err := n.Client.Block(ctx, nil)
+	currentBlock, err := n.fetchBlock(ctx, nil)
 	if err != nil {
 		log.Error().Err(err).Msgf("failed to sync with latest block")
-		return
-	}
-
-	currentBlock := currentBlockResp.Block
-	if currentBlock == nil {
-		log.Error().Err(err).Msgf("no block returned when requesting latest block")
-		return
+		return false
 	}
 
 	// Check the latest known block height
@@ -311,23 +713,19 @@ func (n *Node) syncBlocks(ctx context.Context) {
 		latestBlockHeight = latestBlock.Height
 	}
 
+	found := currentBlock.Height > latestBlockHeight
+
 	// Go back to a maximum of 20 blocks
 	if currentBlock.Height-latestBlockHeight > 20 {
 		latestBlockHeight = currentBlock.Height - 20
 	}
 
 	// Fetch all skipped blocks since latest known block
-	for height := latestBlockHeight + 1; height < currentBlock.Height; height++ {
-		blockResp, err := n.Client.Block(ctx, &height)
-		if err != nil {
-			log.Error().Err(err).Msgf("failed to sync with latest block")
-			continue
-		}
-
+	for _, block := range n.fetchBlocks(ctx, latestBlockHeight+1, currentBlock.Height-1) {
 		n.handleEvent(ctx, EventNewBlock, &ctypes.ResultEvent{
 			Query: "",
 			Data: types.EventDataNewBlock{
-				Block: blockResp.Block,
+				Block: block,
 			},
 			Events: make(map[string][]string),
 		})
@@ -336,45 +734,48 @@ func (n *Node) syncBlocks(ctx context.Context) {
 	n.handleEvent(ctx, EventNewBlock, &ctypes.ResultEvent{
 		Query: "",
 		Data: types.EventDataNewBlock{
-			Block: currentBlockResp.Block,
+			Block: currentBlock,
 		},
 		Events: make(map[string][]string),
 	})
 
-	n.saveLatestBlock(currentBlockResp.Block)
+	n.saveLatestBlock(currentBlock)
+
+	return found
 }
 
 func (n *Node) Stop(ctx context.Context) error {
-	if !n.IsRunning() {
-		return nil
+	// The websocket is closed when the start loop exits
+	if closer, ok := n.queryConn.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("failed to close query connection: %w", err)
+		}
 	}
-
-	// if err := n.Client.UnsubscribeAll(ctx, "cosmos-validator-watcher"); err != nil {
-	// 	return fmt.Errorf("failed to unsubscribe from all events: %w", err)
-	// }
-
-	// if err := n.Client.Stop(); err != nil {
-	// 	return fmt.Errorf("failed to stop client: %w", err)
-	// }
-
 	return nil
 }
 
-func (n *Node) Subscribe(ctx context.Context, eventType string) (<-chan ctypes.ResultEvent, error) {
-	if n.disableWebsocket {
-		return make(chan ctypes.ResultEvent), nil
+func (n *Node) setStreaming(ctx context.Context, streaming bool) {
+	if n.streaming.Swap(streaming) == streaming {
+		return
 	}
 
-	if res, ok := n.subscriptions[eventType]; ok {
-		return res, nil
+	for _, onStreaming := range n.onStreaming {
+		onStreaming(ctx, n, streaming)
 	}
+}
 
-	out, err := n.Client.Subscribe(ctx, "cosmos-validator-watcher", fmt.Sprintf("tm.event='%s'", eventType), 10)
-	if err != nil {
-		return nil, fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+// resetTimer stops the timer and drains its channel before resetting it, so
+// that a tick fired meanwhile isn't received right after the reset.
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
 	}
+	timer.Reset(d)
+}
 
-	n.subscriptions[eventType] = out
-
-	return n.subscriptions[eventType], nil
+func eventQuery(eventType string) string {
+	return fmt.Sprintf("tm.event='%s'", eventType)
 }
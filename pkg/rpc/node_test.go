@@ -0,0 +1,205 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+	"github.com/gorilla/websocket"
+	"gotest.tools/assert"
+)
+
+func TestNodeSyncWindow(t *testing.T) {
+	t.Run("Default Window", func(t *testing.T) {
+		node := newTestNode(t, "http://node-a:26657", 100)
+
+		assert.Equal(t, defaultSyncWindow, node.SyncWindow())
+		assert.Assert(t, node.IsSynced())
+	})
+
+	t.Run("Configured Window", func(t *testing.T) {
+		node := newTestNode(t, "http://node-a:26657", 100)
+		SyncWindow(time.Second)(node)
+		node.status.Store(&ctypes.ResultStatus{
+			SyncInfo: ctypes.SyncInfo{
+				LatestBlockHeight: 100,
+				LatestBlockTime:   time.Now().Add(-10 * time.Second),
+			},
+		})
+
+		assert.Equal(t, time.Second, node.SyncWindow())
+		assert.Assert(t, !node.IsSynced())
+	})
+
+	t.Run("Derived From Block Time", func(t *testing.T) {
+		node := newTestNode(t, "http://node-a:26657", 100)
+
+		// Slow chain: one block every minute
+		start := time.Now().Add(-10 * time.Minute)
+		for i := int64(0); i < 5; i++ {
+			node.saveLatestBlock(&types.Block{Header: types.Header{
+				Height: 100 + i,
+				Time:   start.Add(time.Duration(i) * time.Minute),
+			}})
+		}
+
+		assert.Equal(t, time.Minute, node.AverageBlockTime())
+		assert.Equal(t, 20*time.Minute, node.SyncWindow())
+		assert.Assert(t, node.IsSynced())
+
+		// Fast chain: the window doesn't go below the minimum
+		fast := newTestNode(t, "http://node-b:26657", 100)
+		fast.saveLatestBlock(&types.Block{Header: types.Header{Height: 101, Time: start}})
+		fast.saveLatestBlock(&types.Block{Header: types.Header{Height: 111, Time: start.Add(5 * time.Second)}})
+
+		assert.Equal(t, 500*time.Millisecond, fast.AverageBlockTime())
+		assert.Equal(t, minSyncWindow, fast.SyncWindow())
+	})
+}
+
+// newStreamingServer starts a node serving its status and latest block (at
+// height 100) over RPC. Each websocket connection acknowledges the
+// subscriptions, streams a new block at height 100 + the connection number
+// and gets closed. It returns the number of websocket connections.
+func newStreamingServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	upgrader := websocket.Upgrader{}
+	connections := &atomic.Int32{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/websocket" {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			assert.NilError(t, err)
+			defer conn.Close()
+			height := 100 + int64(connections.Add(1))
+
+			// The node subscribes to new blocks & validator set updates
+			for i := 0; i < 2; i++ {
+				var req rpctypes.RPCRequest
+				assert.NilError(t, conn.ReadJSON(&req))
+				assert.NilError(t, conn.WriteJSON(rpctypes.NewRPCSuccessResponse(req.ID, &ctypes.ResultEvent{})))
+			}
+
+			result, err := cmtjson.Marshal(&ctypes.ResultEvent{
+				Query: eventQuery(EventNewBlock),
+				Data: types.EventDataNewBlock{
+					Block: &types.Block{Header: types.Header{Height: height, Time: time.Now()}},
+				},
+			})
+			assert.NilError(t, err)
+			assert.NilError(t, conn.WriteJSON(rpctypes.RPCResponse{JSONRPC: "2.0", ID: rpctypes.JSONRPCIntID(0), Result: result}))
+
+			// Give some time to the node to handle the event before disconnecting
+			time.Sleep(100 * time.Millisecond)
+			return
+		}
+
+		var req rpctypes.RPCRequest
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result interface{}
+		switch req.Method {
+		case "status":
+			result = &ctypes.ResultStatus{SyncInfo: ctypes.SyncInfo{LatestBlockHeight: 100, LatestBlockTime: time.Now()}}
+		case "block":
+			result = &ctypes.ResultBlock{Block: &types.Block{Header: types.Header{Height: 100, Time: time.Now()}}}
+		default:
+			t.Errorf("unexpected method: %s", req.Method)
+		}
+		assert.NilError(t, json.NewEncoder(w).Encode(rpctypes.NewRPCSuccessResponse(req.ID, result)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, connections
+}
+
+func TestNodeStart(t *testing.T) {
+	newNode := func(t *testing.T, endpoint string, options ...NodeOption) *Node {
+		client, err := rpchttp.New(endpoint, "/websocket")
+		assert.NilError(t, err)
+		return NewNode(client, options...)
+	}
+
+	// startNode starts the node in the background and returns a function
+	// stopping it
+	startNode := func(t *testing.T, node *Node) func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			assert.NilError(t, node.Start(ctx))
+		}()
+
+		return func() {
+			cancel()
+			<-done
+		}
+	}
+
+	t.Run("Reconnect Websocket", func(t *testing.T) {
+		server, connections := newStreamingServer(t)
+		node := newNode(t, server.URL)
+
+		events, err := node.Subscribe(context.Background(), EventNewBlock)
+		assert.NilError(t, err)
+		stop := startNode(t, node)
+
+		// The subscription is renewed after the connection is closed
+		for _, height := range []int64{101, 102} {
+			select {
+			case evt := <-events:
+				assert.Equal(t, height, evt.Data.(types.EventDataNewBlock).Block.Height)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("no event received at height %d", height)
+			}
+		}
+		assert.Assert(t, node.IsRunning())
+		assert.Assert(t, connections.Load() >= 2)
+
+		stop()
+		assert.Assert(t, !node.IsRunning())
+		assert.Assert(t, !node.IsStreaming())
+	})
+
+	t.Run("Disable Websocket", func(t *testing.T) {
+		server, connections := newStreamingServer(t)
+
+		node := newNode(t, server.URL, DisableWebsocket())
+
+		polled := make(chan int64, 1)
+		node.OnEvent(EventNewBlock, func(ctx context.Context, n *Node, event *ctypes.ResultEvent) error {
+			select {
+			case polled <- event.Data.(types.EventDataNewBlock).Block.Height:
+			default:
+			}
+			return nil
+		})
+		events, err := node.Subscribe(context.Background(), EventNewBlock)
+		assert.NilError(t, err)
+		defer startNode(t, node)()
+
+		// The latest block is polled right away
+		select {
+		case height := <-polled:
+			assert.Equal(t, int64(100), height)
+		case <-time.After(5 * time.Second):
+			t.Fatal("latest block not polled")
+		}
+
+		select {
+		case <-events:
+			t.Fatal("unexpected event received while the websocket is disabled")
+		case <-time.After(100 * time.Millisecond):
+		}
+		assert.Equal(t, int32(0), connections.Load())
+		assert.Assert(t, !node.IsStreaming())
+	})
+}
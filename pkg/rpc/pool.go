@@ -3,31 +3,92 @@ package rpc
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 )
 
+type PoolOption func(*Pool)
+
+// QueryAttempts sets the maximum number of nodes a failed query is sent to.
+func QueryAttempts(attempts int) PoolOption {
+	return func(p *Pool) {
+		if attempts > 0 {
+			p.queryAttempts = attempts
+		}
+	}
+}
+
+// MaxLag sets the number of blocks a node can lag behind the best node before
+// being considered unhealthy (0 to disable).
+func MaxLag(blocks int64) PoolOption {
+	return func(p *Pool) {
+		p.maxLag = blocks
+	}
+}
+
+// StuckTimeout sets for how long the height of a node can stop advancing
+// while other nodes are ahead before being considered unhealthy (0 to disable).
+func StuckTimeout(timeout time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.stuckTimeout = timeout
+	}
+}
+
 type Pool struct {
 	ChainID string
 	Nodes   []*Node
 
 	started     chan struct{}
 	startedOnce sync.Once
+	next        atomic.Uint64 // round-robin counter among equally healthy nodes
+
+	queryAttempts int
+	maxLag        int64
+	stuckTimeout  time.Duration
+
+	fanIn *blockFanIn
+
+	// Node used by the latest query of each named connection
+	lastUsedMu sync.Mutex
+	lastUsed   map[string]*Node
 }
 
-func NewPool(chainID string, nodes []*Node) *Pool {
-	return &Pool{
-		ChainID:     chainID,
-		Nodes:       nodes,
-		started:     make(chan struct{}),
-		startedOnce: sync.Once{},
+func NewPool(chainID string, nodes []*Node, options ...PoolOption) *Pool {
+	pool := &Pool{
+		ChainID:       chainID,
+		Nodes:         nodes,
+		started:       make(chan struct{}),
+		startedOnce:   sync.Once{},
+		queryAttempts: 3,
+		lastUsed:      make(map[string]*Node),
+		fanIn:         newBlockFanIn(),
+	}
+
+	for _, opt := range options {
+		opt(pool)
+	}
+
+	for _, node := range nodes {
+		node.OnEvent(EventNewBlock, pool.fanIn.receiveBlock)
 	}
+
+	return pool
 }
 
 func (p *Pool) Start(ctx context.Context) error {
 	errg, ctx := errgroup.WithContext(ctx)
+
+	// Dispatch the blocks of all nodes
+	errg.Go(func() error {
+		p.fanIn.run(ctx)
+		return nil
+	})
 	for _, node := range p.Nodes {
 		node := node
 		// Start node
@@ -74,11 +135,150 @@ func (p *Pool) Started() chan struct{} {
 	return p.started
 }
 
+// GetSyncedNode returns the healthiest synced node, in a round-robin fashion
+// among equally healthy nodes.
 func (p *Pool) GetSyncedNode() *Node {
+	nodes, scores := p.rankSyncedNodes()
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	return nodes[p.nextBest(scores)]
+}
+
+// GetSyncedNodes returns all synced nodes, starting with the healthiest one
+// picked in a round-robin fashion like GetSyncedNode, followed by the others
+// from the healthiest to the least healthy.
+func (p *Pool) GetSyncedNodes() []*Node {
+	nodes, scores := p.rankSyncedNodes()
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	first := p.nextBest(scores)
+	sorted := make([]*Node, 0, len(nodes))
+	sorted = append(sorted, nodes[first])
+	sorted = append(sorted, nodes[:first]...)
+	sorted = append(sorted, nodes[first+1:]...)
+
+	return sorted
+}
+
+// nextBest returns the index of the next node to use among the ones sharing
+// the best score, given the sorted scores of the ranked nodes.
+func (p *Pool) nextBest(scores []int) int {
+	best := 1
+	for best < len(scores) && scores[best] == scores[0] {
+		best++
+	}
+
+	return int(p.next.Add(1) % uint64(best))
+}
+
+// Conn returns a gRPC client connection to query modules through the pool:
+// each query is sent to the healthiest node and retried on the next healthy
+// nodes when the node fails to answer.
+func (p *Pool) Conn() grpc.ClientConnInterface {
+	return &poolConn{pool: p}
+}
+
+// ConnFor returns a pool connection like Conn, remembering the node used by
+// the latest query under the given name (eg. the name of the watcher).
+func (p *Pool) ConnFor(name string) grpc.ClientConnInterface {
+	return &poolConn{pool: p, name: name}
+}
+
+// LastUsedNodes returns the node used by the latest query of each named connection.
+func (p *Pool) LastUsedNodes() map[string]*Node {
+	p.lastUsedMu.Lock()
+	defer p.lastUsedMu.Unlock()
+
+	nodes := make(map[string]*Node, len(p.lastUsed))
+	for name, node := range p.lastUsed {
+		nodes[name] = node
+	}
+	return nodes
+}
+
+func (p *Pool) setLastUsed(name string, node *Node) {
+	if name == "" {
+		return
+	}
+
+	p.lastUsedMu.Lock()
+	defer p.lastUsedMu.Unlock()
+	p.lastUsed[name] = node
+}
+
+// Score returns the health score of the node (lower is healthier), based on
+// its recent error rate & latency as well as its lag behind the best node.
+func (p *Pool) Score(node *Node) int {
+	return healthScore(node.health.ErrorRate(), node.health.Latency(), p.BestHeight()-node.Height())
+}
+
+// BestHeight returns the highest block height known among the synced nodes.
+// Unsynced nodes are ignored so that a single node reporting a bogus height
+// doesn't make all the other nodes lag behind.
+func (p *Pool) BestHeight() int64 {
+	best := int64(0)
 	for _, node := range p.Nodes {
 		if node.IsSynced() {
-			return node
+			best = max(best, node.Height())
+		}
+	}
+	return best
+}
+
+// Lag returns the number of blocks the node is behind the best synced node.
+func (p *Pool) Lag(node *Node) int64 {
+	return p.BestHeight() - node.Height()
+}
+
+// IsHealthy returns true when the node is synced and neither lags too far
+// behind the best node nor is stuck while other nodes are advancing.
+func (p *Pool) IsHealthy(node *Node) bool {
+	return p.isHealthy(node, p.BestHeight())
+}
+
+func (p *Pool) isHealthy(node *Node, bestHeight int64) bool {
+	if !node.IsSynced() {
+		return false
+	}
+
+	lag := bestHeight - node.Height()
+	if p.maxLag > 0 && lag > p.maxLag {
+		return false
+	}
+	if p.stuckTimeout > 0 && lag > 0 && node.StalledFor() > p.stuckTimeout {
+		return false
+	}
+
+	return true
+}
+
+// rankSyncedNodes returns healthy nodes sorted by health score along with their scores.
+func (p *Pool) rankSyncedNodes() ([]*Node, []int) {
+	bestHeight := p.BestHeight()
+
+	nodes := make([]*Node, 0, len(p.Nodes))
+	for _, node := range p.Nodes {
+		if p.isHealthy(node, bestHeight) {
+			nodes = append(nodes, node)
 		}
 	}
-	return nil
+
+	scores := make(map[*Node]int, len(nodes))
+	for _, node := range nodes {
+		scores[node] = healthScore(node.health.ErrorRate(), node.health.Latency(), bestHeight-node.Height())
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return scores[nodes[i]] < scores[nodes[j]]
+	})
+
+	sorted := make([]int, len(nodes))
+	for i, node := range nodes {
+		sorted[i] = scores[node]
+	}
+
+	return nodes, sorted
 }
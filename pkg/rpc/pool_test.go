@@ -0,0 +1,151 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/rpc/client/http"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"gotest.tools/assert"
+)
+
+func newTestNode(t *testing.T, endpoint string, height int64) *Node {
+	client, err := http.New(endpoint, "/websocket")
+	assert.NilError(t, err)
+
+	node := NewNode(client)
+	node.status.Store(&ctypes.ResultStatus{
+		SyncInfo: ctypes.SyncInfo{
+			LatestBlockHeight: height,
+			LatestBlockTime:   time.Now(),
+		},
+	})
+
+	return node
+}
+
+func TestPool(t *testing.T) {
+	t.Run("Round Robin Among Healthy Nodes", func(t *testing.T) {
+		nodeA := newTestNode(t, "http://node-a:26657", 100)
+		nodeB := newTestNode(t, "http://node-b:26657", 100)
+		pool := NewPool("chain-42", []*Node{nodeA, nodeB})
+
+		selected := map[*Node]int{}
+		for i := 0; i < 4; i++ {
+			selected[pool.GetSyncedNode()]++
+		}
+		assert.Equal(t, 2, selected[nodeA])
+		assert.Equal(t, 2, selected[nodeB])
+	})
+
+	t.Run("Skip Erroring Node", func(t *testing.T) {
+		nodeA := newTestNode(t, "http://node-a:26657", 100)
+		nodeB := newTestNode(t, "http://node-b:26657", 100)
+		pool := NewPool("chain-42", []*Node{nodeA, nodeB})
+
+		for i := 0; i < 5; i++ {
+			nodeA.health.observe(10*time.Millisecond, errors.New("connection refused"))
+			nodeB.health.observe(10*time.Millisecond, nil)
+		}
+
+		for i := 0; i < 4; i++ {
+			assert.Equal(t, nodeB, pool.GetSyncedNode())
+		}
+	})
+
+	t.Run("Skip Lagging Node", func(t *testing.T) {
+		nodeA := newTestNode(t, "http://node-a:26657", 100)
+		nodeB := newTestNode(t, "http://node-b:26657", 80)
+		pool := NewPool("chain-42", []*Node{nodeA, nodeB})
+
+		assert.Equal(t, int64(100), pool.BestHeight())
+		assert.Equal(t, 0, pool.Score(nodeA))
+		assert.Equal(t, 20, pool.Score(nodeB))
+		for i := 0; i < 4; i++ {
+			assert.Equal(t, nodeA, pool.GetSyncedNode())
+		}
+	})
+
+	t.Run("Exclude Node Beyond Max Lag", func(t *testing.T) {
+		nodeA := newTestNode(t, "http://node-a:26657", 100)
+		nodeB := newTestNode(t, "http://node-b:26657", 97)
+		pool := NewPool("chain-42", []*Node{nodeA, nodeB}, MaxLag(2))
+
+		assert.Equal(t, int64(3), pool.Lag(nodeB))
+		assert.Assert(t, pool.IsHealthy(nodeA))
+		assert.Assert(t, !pool.IsHealthy(nodeB))
+		assert.Equal(t, 1, len(pool.GetSyncedNodes()))
+	})
+
+	t.Run("Ignore Outlier Node Height", func(t *testing.T) {
+		nodeA := newTestNode(t, "http://node-a:26657", 100)
+		nodeB := newTestNode(t, "http://node-b:26657", 99)
+
+		// Node C reports a bogus height but its latest block is old
+		nodeC := newTestNode(t, "http://node-c:26657", 1_000_000)
+		nodeC.status.Store(&ctypes.ResultStatus{
+			SyncInfo: ctypes.SyncInfo{
+				LatestBlockHeight: 1_000_000,
+				LatestBlockTime:   time.Now().Add(-time.Hour),
+			},
+		})
+		pool := NewPool("chain-42", []*Node{nodeA, nodeB, nodeC}, MaxLag(5))
+
+		assert.Equal(t, int64(100), pool.BestHeight())
+		assert.Assert(t, pool.IsHealthy(nodeA))
+		assert.Assert(t, pool.IsHealthy(nodeB))
+		assert.Assert(t, !pool.IsHealthy(nodeC))
+		assert.Equal(t, 2, len(pool.GetSyncedNodes()))
+	})
+
+	t.Run("Exclude Stuck Node", func(t *testing.T) {
+		nodeA := newTestNode(t, "http://node-a:26657", 100)
+		nodeB := newTestNode(t, "http://node-b:26657", 99)
+		pool := NewPool("chain-42", []*Node{nodeA, nodeB}, StuckTimeout(time.Minute))
+
+		nodeB.trackHeight(nodeB.Height(), time.Now())
+		assert.Assert(t, pool.IsHealthy(nodeB))
+
+		// Height of node B hasn't advanced for 2 minutes
+		nodeB.heightAdvancedAt.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+		assert.Assert(t, nodeB.StalledFor() > time.Minute)
+		assert.Assert(t, !pool.IsHealthy(nodeB))
+
+		// The best node isn't stuck as long as no other node is ahead
+		nodeA.trackHeight(nodeA.Height(), time.Now())
+		nodeA.heightAdvancedAt.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+		assert.Assert(t, pool.IsHealthy(nodeA))
+	})
+
+	t.Run("Node Dropping Out", func(t *testing.T) {
+		nodeA := newTestNode(t, "http://node-a:26657", 100)
+		nodeB := newTestNode(t, "http://node-b:26657", 100)
+		nodeC := newTestNode(t, "http://node-c:26657", 100)
+		pool := NewPool("chain-42", []*Node{nodeA, nodeB, nodeC})
+
+		assert.Equal(t, 3, len(pool.GetSyncedNodes()))
+
+		// Node A is no longer synced
+		nodeA.status.Store(&ctypes.ResultStatus{
+			SyncInfo: ctypes.SyncInfo{
+				LatestBlockHeight: 100,
+				LatestBlockTime:   time.Now().Add(-time.Hour),
+			},
+		})
+
+		first := map[*Node]int{}
+		for i := 0; i < 4; i++ {
+			nodes := pool.GetSyncedNodes()
+			assert.Equal(t, 2, len(nodes))
+			for _, node := range nodes {
+				assert.Assert(t, node != nil)
+				assert.Assert(t, node != nodeA)
+			}
+			assert.Assert(t, nodes[0] != nodes[1])
+			first[nodes[0]]++
+		}
+		assert.Equal(t, 2, first[nodeB])
+		assert.Equal(t, 2, first[nodeC])
+	})
+}
@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNoNodeAvailable is returned by pool queries when no node is synced.
+var ErrNoNodeAvailable = status.Error(codes.Unavailable, "no node available")
+
+// poolConn is a gRPC client connection sending queries to the healthiest node
+// of the pool and retrying failed queries on the next healthy node. The node
+// used by the latest query is saved under the name of the connection.
+type poolConn struct {
+	pool *Pool
+	name string
+}
+
+var _ grpc.ClientConnInterface = &poolConn{}
+
+func (c *poolConn) Invoke(ctx context.Context, method string, req, reply interface{}, opts ...grpc.CallOption) error {
+	return c.pool.withNode(ctx, c.name, "query "+method, func(conn grpc.ClientConnInterface) error {
+		return conn.Invoke(ctx, method, req, reply, opts...)
+	})
+}
+
+func (c *poolConn) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, fmt.Errorf("streaming rpc not supported")
+}
+
+// WithNodeConn calls fn with a connection to a single node of the pool, so
+// that a sequence of queries (eg. the pages of a list) is answered by the same
+// node. The whole sequence is retried on the next healthy node when the node
+// fails to answer. The node used is saved under the given name.
+func (p *Pool) WithNodeConn(ctx context.Context, name string, fn func(conn grpc.ClientConnInterface) error) error {
+	return p.withNode(ctx, name, "queries", fn)
+}
+
+func (p *Pool) withNode(ctx context.Context, name string, what string, fn func(conn grpc.ClientConnInterface) error) error {
+	nodes := p.GetSyncedNodes()
+	if len(nodes) == 0 {
+		return ErrNoNodeAvailable
+	}
+
+	attempts := min(len(nodes), p.queryAttempts)
+
+	var err error
+	for i, node := range nodes[:attempts] {
+		p.setLastUsed(name, node)
+		err = fn(node.Conn())
+		if err == nil || ctx.Err() != nil || !isRetryableError(err) {
+			return err
+		}
+
+		if i < attempts-1 {
+			log.Warn().Err(err).
+				Str("node", node.Redacted()).
+				Msgf("retrying %s on another node", what)
+		}
+	}
+
+	return fmt.Errorf("query failed after %d attempts: %w", attempts, err)
+}
+
+// isRetryableError returns true when the error is caused by the node itself
+// (eg. connection error or unavailable service) rather than by the query.
+func isRetryableError(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		// Not a gRPC status: the query didn't reach the application
+		return true
+	}
+
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted, codes.Internal:
+		return true
+	default:
+		return false
+	}
+}
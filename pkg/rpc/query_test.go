@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	upgrade "cosmossdk.io/x/upgrade/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gotest.tools/assert"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	assert.Equal(t, true, isRetryableError(errors.New("connection refused")))
+	assert.Equal(t, true, isRetryableError(status.Error(codes.Unavailable, "unavailable")))
+	assert.Equal(t, false, isRetryableError(status.Error(codes.InvalidArgument, "invalid voter")))
+	assert.Equal(t, false, isRetryableError(status.Error(codes.NotFound, "not found")))
+}
+
+func TestPoolConn(t *testing.T) {
+	t.Run("No Node Available", func(t *testing.T) {
+		pool := NewPool("chain-42", nil)
+
+		_, err := upgrade.NewQueryClient(pool.Conn()).CurrentPlan(context.Background(), &upgrade.QueryCurrentPlanRequest{})
+		assert.Equal(t, ErrNoNodeAvailable, err)
+	})
+
+	t.Run("Retry On Next Nodes", func(t *testing.T) {
+		pool := NewPool("chain-42", []*Node{
+			newTestNode(t, "http://127.0.0.1:1", 100),
+			newTestNode(t, "http://127.0.0.1:2", 100),
+			newTestNode(t, "http://127.0.0.1:3", 100),
+		}, QueryAttempts(2))
+
+		_, err := upgrade.NewQueryClient(pool.Conn()).CurrentPlan(context.Background(), &upgrade.QueryCurrentPlanRequest{})
+		assert.Assert(t, err != nil)
+		assert.Assert(t, strings.Contains(err.Error(), "query failed after 2 attempts"), err.Error())
+	})
+
+	t.Run("Remember Last Used Node", func(t *testing.T) {
+		nodeA := newTestNode(t, "http://127.0.0.1:1", 100)
+		nodeB := newTestNode(t, "http://127.0.0.1:2", 100)
+		pool := NewPool("chain-42", []*Node{nodeA, nodeB}, QueryAttempts(1))
+
+		_, err := upgrade.NewQueryClient(pool.ConnFor("upgrade")).CurrentPlan(context.Background(), &upgrade.QueryCurrentPlanRequest{})
+		assert.Assert(t, err != nil)
+
+		lastUsed := pool.LastUsedNodes()
+		assert.Equal(t, 1, len(lastUsed))
+		assert.Assert(t, lastUsed["upgrade"] == nodeA || lastUsed["upgrade"] == nodeB)
+
+		// The failure is reported as the last error of the node
+		_, lastErr := lastUsed["upgrade"].LastError()
+		assert.Assert(t, lastErr != nil)
+	})
+}
+
+// sequenceConn answers a given number of queries before failing (0 to never fail).
+type sequenceConn struct {
+	calls     int
+	failAfter int
+}
+
+func (c *sequenceConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	c.calls++
+	if c.failAfter > 0 && c.calls > c.failAfter {
+		return status.Error(codes.Unavailable, "node is down")
+	}
+	return nil
+}
+
+func (c *sequenceConn) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestPoolWithNodeConn(t *testing.T) {
+	nodeA := newTestNode(t, "http://node-a:26657", 100)
+	nodeB := newTestNode(t, "http://node-b:26657", 100)
+	connA := &sequenceConn{failAfter: 1}
+	connB := &sequenceConn{}
+	QueryConn(connA)(nodeA)
+	QueryConn(connB)(nodeB)
+	pool := NewPool("chain-42", []*Node{nodeA, nodeB}, QueryAttempts(2))
+
+	// Node A fails in the middle of the sequence: the whole sequence is sent
+	// again to node B
+	err := pool.WithNodeConn(context.Background(), "validators", func(conn grpc.ClientConnInterface) error {
+		for i := 0; i < 3; i++ {
+			if err := conn.Invoke(context.Background(), "/query", nil, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+
+	assert.Equal(t, 3, connB.calls)
+	assert.Assert(t, connA.calls == 0 || connA.calls == 2)
+	assert.Assert(t, pool.LastUsedNodes()["validators"] == nodeB)
+}
@@ -0,0 +1,222 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	upgrade "cosmossdk.io/x/upgrade/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	distribution "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	gov "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	govbeta "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	staking "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/cosmos/gogoproto/jsonpb"
+	"github.com/cosmos/gogoproto/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// restConn is a gRPC client connection sending module queries to the REST
+// (LCD) API of a node. Only the queries made by the watchers are supported.
+type restConn struct {
+	endpoint *url.URL
+	client   *http.Client
+}
+
+var _ grpc.ClientConnInterface = &restConn{}
+
+// NewRESTConn returns a gRPC client connection querying modules through the
+// REST (LCD) API available at the given endpoint.
+func NewRESTConn(endpoint string, client *http.Client) (grpc.ClientConnInterface, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rest endpoint: %w", err)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &restConn{
+		endpoint: u,
+		client:   client,
+	}, nil
+}
+
+func (c *restConn) Invoke(ctx context.Context, method string, req, reply interface{}, _ ...grpc.CallOption) error {
+	path, params, err := restRoute(method, req)
+	if err != nil {
+		return err
+	}
+
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	if len(params) > 0 {
+		query := u.Query()
+		for key, values := range params {
+			query[key] = values
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to create request: %v", err)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to query %s: %v", method, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to read response of %s: %v", method, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return restError(resp.StatusCode, body)
+	}
+
+	message, ok := reply.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "unsupported reply type %T", reply)
+	}
+
+	unmarshaler := jsonpb.Unmarshaler{
+		AllowUnknownFields: true,
+		AnyResolver:        tolerantResolver{},
+	}
+	if err := unmarshaler.Unmarshal(bytes.NewReader(body), message); err != nil {
+		return status.Errorf(codes.Internal, "failed to decode response of %s: %v", method, err)
+	}
+
+	return nil
+}
+
+func (c *restConn) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, fmt.Errorf("streaming rpc not supported")
+}
+
+// restRoute returns the REST path and query parameters of a gRPC query.
+func restRoute(method string, req interface{}) (string, url.Values, error) {
+	switch req := req.(type) {
+	case *staking.QueryValidatorsRequest:
+		params := paginationParams(req.Pagination)
+		if req.Status != "" {
+			params.Set("status", req.Status)
+		}
+		return "/cosmos/staking/v1beta1/validators", params, nil
+
+	case *gov.QueryProposalsRequest:
+		params := paginationParams(req.Pagination)
+		if req.ProposalStatus != gov.StatusNil {
+			params.Set("proposal_status", req.ProposalStatus.String())
+		}
+		return "/cosmos/gov/v1/proposals", params, nil
+
+	case *gov.QueryVoteRequest:
+		return fmt.Sprintf("/cosmos/gov/v1/proposals/%d/votes/%s", req.ProposalId, url.PathEscape(req.Voter)), nil, nil
+
+	case *govbeta.QueryProposalsRequest:
+		params := paginationParams(req.Pagination)
+		if req.ProposalStatus != govbeta.StatusNil {
+			params.Set("proposal_status", req.ProposalStatus.String())
+		}
+		return "/cosmos/gov/v1beta1/proposals", params, nil
+
+	case *govbeta.QueryVoteRequest:
+		return fmt.Sprintf("/cosmos/gov/v1beta1/proposals/%d/votes/%s", req.ProposalId, url.PathEscape(req.Voter)), nil, nil
+
+	case *distribution.QueryValidatorCommissionRequest:
+		return fmt.Sprintf("/cosmos/distribution/v1beta1/validators/%s/commission", url.PathEscape(req.ValidatorAddress)), nil, nil
+
+	case *upgrade.QueryCurrentPlanRequest:
+		return "/cosmos/upgrade/v1beta1/current_plan", nil, nil
+	}
+
+	return "", nil, status.Errorf(codes.Unimplemented, "query %s not supported over rest", method)
+}
+
+func paginationParams(pagination *query.PageRequest) url.Values {
+	params := url.Values{}
+	if pagination == nil {
+		return params
+	}
+
+	if len(pagination.Key) > 0 {
+		params.Set("pagination.key", base64.StdEncoding.EncodeToString(pagination.Key))
+	}
+	if pagination.Offset > 0 {
+		params.Set("pagination.offset", strconv.FormatUint(pagination.Offset, 10))
+	}
+	if pagination.Limit > 0 {
+		params.Set("pagination.limit", strconv.FormatUint(pagination.Limit, 10))
+	}
+	if pagination.CountTotal {
+		params.Set("pagination.count_total", "true")
+	}
+	if pagination.Reverse {
+		params.Set("pagination.reverse", "true")
+	}
+	return params
+}
+
+// restError converts an error response of the REST API into a gRPC status
+// error, keeping the gRPC code returned by the gateway when available.
+func restError(statusCode int, body []byte) error {
+	var resp struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Code != 0 {
+		return status.Error(codes.Code(resp.Code), resp.Message)
+	}
+
+	code := codes.Unknown
+	switch {
+	case statusCode == http.StatusNotFound:
+		code = codes.NotFound
+	case statusCode == http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case statusCode >= 500:
+		code = codes.Unavailable
+	}
+	return status.Errorf(code, "rest api returned %d: %s", statusCode, strings.TrimSpace(string(body)))
+}
+
+// tolerantResolver resolves the types of Any values from the registered
+// messages. Unknown types (eg. messages of chain specific modules) resolve to
+// an empty message so that they don't break the decoding of the response.
+type tolerantResolver struct{}
+
+func (tolerantResolver) Resolve(typeURL string) (proto.Message, error) {
+	name := typeURL
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+
+	if messageType := proto.MessageType(name); messageType != nil {
+		if message, ok := reflect.New(messageType.Elem()).Interface().(proto.Message); ok {
+			return message, nil
+		}
+	}
+	return &unknownMessage{}, nil
+}
+
+// unknownMessage is a placeholder message ignoring all fields.
+type unknownMessage struct{}
+
+func (*unknownMessage) Reset()         {}
+func (*unknownMessage) String() string { return "unknown" }
+func (*unknownMessage) ProtoMessage()  {}
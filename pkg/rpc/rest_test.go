@@ -0,0 +1,140 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	upgrade "cosmossdk.io/x/upgrade/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	distribution "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	gov "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	staking "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gotest.tools/assert"
+)
+
+func newTestRESTServer(t *testing.T) *httptest.Server {
+	responses := map[string]string{
+		"/cosmos/staking/v1beta1/validators?pagination.limit=3000": `{
+			"validators": [{
+				"operator_address": "cosmosvaloper1uxlf7mvr8nep3gm7udf2u9remms2jyjqvwdul2",
+				"consensus_pubkey": {"@type": "/cosmos.crypto.ed25519.PubKey", "key": "v1ZYobpoOxAhXSOXPt8ZNOC5/sMKGJPxyyXHwo5Byl0="},
+				"jailed": false,
+				"status": "BOND_STATUS_BONDED",
+				"tokens": "1000000",
+				"delegator_shares": "1000000.000000000000000000",
+				"description": {"moniker": "kiln"},
+				"commission": {"commission_rates": {"rate": "0.050000000000000000", "max_rate": "0.200000000000000000", "max_change_rate": "0.010000000000000000"}},
+				"unknown_field": "ignored"
+			}],
+			"pagination": {"next_key": null, "total": "1"}
+		}`,
+		"/cosmos/gov/v1/proposals?proposal_status=PROPOSAL_STATUS_VOTING_PERIOD": `{
+			"proposals": [{
+				"id": "42",
+				"messages": [
+					{"@type": "/chain.custom.v1.MsgUnknown", "field": "value"},
+					{"@type": "/cosmos.upgrade.v1beta1.MsgSoftwareUpgrade", "authority": "cosmos10d07y265gmmuvt4z0w9aw880jnsr700j6zn9kn", "plan": {"name": "v42", "height": "123456"}}
+				],
+				"status": "PROPOSAL_STATUS_VOTING_PERIOD",
+				"voting_end_time": "2024-06-01T00:00:00Z"
+			}]
+		}`,
+		"/cosmos/upgrade/v1beta1/current_plan": `{"plan": null}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cosmos/gov/v1/proposals/42/votes/cosmos1uxlf7mvr8nep3gm7udf2u9remms2jyjqw8dgv9" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code": 3, "message": "voter not found for proposal 42", "details": []}`))
+			return
+		}
+		if r.URL.Path == "/cosmos/distribution/v1beta1/validators/cosmosvaloper1uxlf7mvr8nep3gm7udf2u9remms2jyjqvwdul2/commission" {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("bad gateway"))
+			return
+		}
+
+		body, ok := responses[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestRESTConn(t *testing.T) {
+	server := newTestRESTServer(t)
+	ctx := context.Background()
+
+	conn, err := NewRESTConn(server.URL, nil)
+	assert.NilError(t, err)
+
+	t.Run("Staking Validators", func(t *testing.T) {
+		resp, err := staking.NewQueryClient(conn).Validators(ctx, &staking.QueryValidatorsRequest{
+			Pagination: &query.PageRequest{Limit: 3000},
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(resp.Validators))
+
+		validator := resp.Validators[0]
+		assert.Equal(t, "kiln", validator.Description.Moniker)
+		assert.Equal(t, staking.Bonded, validator.Status)
+		assert.Equal(t, int64(1000000), validator.Tokens.Int64())
+		assert.Equal(t, "/cosmos.crypto.ed25519.PubKey", validator.ConsensusPubkey.TypeUrl)
+		assert.Equal(t, 34, len(validator.ConsensusPubkey.Value))
+		assert.Equal(t, uint64(1), resp.Pagination.Total)
+	})
+
+	t.Run("Gov Proposals", func(t *testing.T) {
+		resp, err := gov.NewQueryClient(conn).Proposals(ctx, &gov.QueryProposalsRequest{
+			ProposalStatus: gov.StatusVotingPeriod,
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(resp.Proposals))
+
+		proposal := resp.Proposals[0]
+		assert.Equal(t, uint64(42), proposal.Id)
+		assert.Equal(t, 2, len(proposal.Messages))
+		assert.Equal(t, "/chain.custom.v1.MsgUnknown", proposal.Messages[0].TypeUrl)
+
+		var msg upgrade.MsgSoftwareUpgrade
+		assert.NilError(t, msg.Unmarshal(proposal.Messages[1].Value))
+		assert.Equal(t, "v42", msg.Plan.Name)
+		assert.Equal(t, int64(123456), msg.Plan.Height)
+	})
+
+	t.Run("Gov Vote Not Found", func(t *testing.T) {
+		_, err := gov.NewQueryClient(conn).Vote(ctx, &gov.QueryVoteRequest{
+			ProposalId: 42,
+			Voter:      "cosmos1uxlf7mvr8nep3gm7udf2u9remms2jyjqw8dgv9",
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Gateway Error", func(t *testing.T) {
+		_, err := distribution.NewQueryClient(conn).ValidatorCommission(ctx, &distribution.QueryValidatorCommissionRequest{
+			ValidatorAddress: "cosmosvaloper1uxlf7mvr8nep3gm7udf2u9remms2jyjqvwdul2",
+		})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, true, isRetryableError(err))
+	})
+
+	t.Run("Upgrade Current Plan", func(t *testing.T) {
+		resp, err := upgrade.NewQueryClient(conn).CurrentPlan(ctx, &upgrade.QueryCurrentPlanRequest{})
+		assert.NilError(t, err)
+		assert.Assert(t, resp.Plan == nil)
+	})
+
+	t.Run("Unsupported Query", func(t *testing.T) {
+		_, err := staking.NewQueryClient(conn).Params(ctx, &staking.QueryParamsRequest{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Delay between two checks of the height for schedules based on blocks
+const scheduleHeightPollInterval = time.Second
+
+// Schedule defines when a periodic task runs: on a wall clock interval
+// randomized by a jitter, or every N blocks.
+type Schedule struct {
+	// Delay between two runs
+	Interval time.Duration
+
+	// Fraction of the interval by which each delay is randomly shortened or
+	// extended (eg. 0.1 for ±10%)
+	Jitter float64
+
+	// Run every N blocks instead of the interval (0 to disable)
+	Blocks int64
+}
+
+// WithDefaultInterval returns the schedule with the given interval when none is set.
+func (s Schedule) WithDefaultInterval(interval time.Duration) Schedule {
+	if s.Interval <= 0 {
+		s.Interval = interval
+	}
+	return s
+}
+
+// Next returns the delay until the next run of an interval schedule.
+func (s Schedule) Next() time.Duration {
+	if s.Jitter <= 0 {
+		return s.Interval
+	}
+
+	jitter := min(s.Jitter, 1)
+	delay := float64(s.Interval) * (1 + jitter*(2*rand.Float64()-1))
+	return max(time.Duration(delay), time.Millisecond)
+}
+
+// Run calls fn right away, then each time the schedule is due until the
+// context is done. The height function returns the latest block height and
+// is only required by schedules based on blocks.
+func (s Schedule) Run(ctx context.Context, height func() int64, fn func()) {
+	fn()
+	s.Loop(ctx, height, fn)
+}
+
+// Loop calls fn each time the schedule is due until the context is done,
+// starting after the first delay.
+func (s Schedule) Loop(ctx context.Context, height func() int64, fn func()) {
+	if s.Blocks > 0 && height != nil {
+		s.runOnBlocks(ctx, height, fn)
+		return
+	}
+
+	timer := time.NewTimer(s.Next())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		fn()
+		timer.Reset(s.Next())
+	}
+}
+
+func (s Schedule) runOnBlocks(ctx context.Context, height func() int64, fn func()) {
+	ticker := time.NewTicker(scheduleHeightPollInterval)
+	defer ticker.Stop()
+
+	lastHeight := height()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		currentHeight := height()
+		if lastHeight == 0 {
+			// Height wasn't known yet: count blocks from now on
+			lastHeight = currentHeight
+			continue
+		}
+		if currentHeight < lastHeight+s.Blocks {
+			continue
+		}
+
+		lastHeight = currentHeight
+		fn()
+	}
+}
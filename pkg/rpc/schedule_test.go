@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	t.Run("Jitter", func(t *testing.T) {
+		schedule := Schedule{Interval: 10 * time.Second, Jitter: 0.1}
+		for i := 0; i < 100; i++ {
+			delay := schedule.Next()
+			assert.Assert(t, delay >= 9*time.Second && delay <= 11*time.Second, delay)
+		}
+
+		assert.Equal(t, 10*time.Second, Schedule{Interval: 10 * time.Second}.Next())
+	})
+
+	t.Run("Default Interval", func(t *testing.T) {
+		assert.Equal(t, time.Minute, Schedule{}.WithDefaultInterval(time.Minute).Interval)
+		assert.Equal(t, time.Second, Schedule{Interval: time.Second}.WithDefaultInterval(time.Minute).Interval)
+	})
+
+	t.Run("Run On Interval", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+		defer cancel()
+
+		runs := 0
+		Schedule{Interval: 10 * time.Millisecond}.Run(ctx, nil, func() {
+			runs++
+		})
+		assert.Assert(t, runs >= 2 && runs <= 6, runs)
+	})
+
+	t.Run("Run On Blocks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var height atomic.Int64
+		height.Store(100)
+
+		runs := make(chan int64, 10)
+		go Schedule{Interval: time.Hour, Blocks: 5}.Run(ctx, height.Load, func() {
+			runs <- height.Load()
+		})
+		assert.Equal(t, int64(100), <-runs)
+
+		// Not enough blocks yet
+		height.Store(104)
+		time.Sleep(scheduleHeightPollInterval + 100*time.Millisecond)
+		assert.Equal(t, 0, len(runs))
+
+		height.Store(105)
+		select {
+		case h := <-runs:
+			assert.Equal(t, int64(105), h)
+		case <-time.After(2 * scheduleHeightPollInterval):
+			t.Fatal("schedule didn't run after 5 blocks")
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	jsonrpcclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
+)
+
+// NewHTTPClient returns an HTTP client for the given endpoint adding the given
+// headers (eg. API keys) to every request. The TLS config is optional.
+func NewHTTPClient(endpoint string, header http.Header, tlsConfig *tls.Config) (*http.Client, error) {
+	client, err := jsonrpcclient.DefaultHTTPClient(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	if tlsConfig != nil {
+		transport, ok := client.Transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("unexpected http transport %T", client.Transport)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if len(header) > 0 {
+		client.Transport = &headerTransport{
+			base:   client.Transport,
+			header: header,
+		}
+	}
+
+	return client, nil
+}
+
+type headerTransport struct {
+	base   http.RoundTripper
+	header http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, values := range t.header {
+		req.Header[name] = values
+	}
+	return t.base.RoundTrip(req)
+}
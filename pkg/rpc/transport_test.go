@@ -0,0 +1,23 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestHTTPClientHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(server.URL, http.Header{"X-Api-Key": {"secret"}}, nil)
+	assert.NilError(t, err)
+
+	resp, err := client.Get(server.URL)
+	assert.NilError(t, err)
+	resp.Body.Close()
+}
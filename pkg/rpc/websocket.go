@@ -0,0 +1,237 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = 25 * time.Second
+)
+
+// eventStream is a websocket connection to a node streaming the events of
+// the subscribed queries. The stream is done as soon as the connection fails
+// or a subscription is rejected by the node.
+type eventStream struct {
+	conn    *websocket.Conn
+	queries []string // queries subscribed when dialing
+	events  chan ctypes.ResultEvent
+	done    chan struct{}
+
+	writeMu   sync.Mutex
+	requestID int
+	closeOnce sync.Once
+	err       error
+}
+
+// dialEventStream opens a websocket connection to the given RPC endpoint and
+// subscribes to the given queries. The given headers are added to the
+// handshake request and the TLS config is optional.
+func dialEventStream(ctx context.Context, endpoint string, extraHeader http.Header, tlsConfig *tls.Config, queries []string) (*eventStream, error) {
+	wsURL, header, err := websocketURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range extraHeader {
+		header[name] = values
+	}
+
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = tlsConfig
+	conn, _, err := dialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial websocket: %w", err)
+	}
+
+	s := &eventStream{
+		conn:    conn,
+		queries: queries,
+		events:  make(chan ctypes.ResultEvent, 10),
+		done:    make(chan struct{}),
+	}
+
+	for _, query := range queries {
+		if err := s.Subscribe(query); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	go s.readLoop()
+	go s.pingLoop()
+
+	return s, nil
+}
+
+// Subscribe sends a subscription request for the given query. A rejected
+// subscription makes the stream done.
+func (s *eventStream) Subscribe(query string) error {
+	s.writeMu.Lock()
+	id := s.requestID
+	s.requestID++
+	s.writeMu.Unlock()
+
+	req, err := rpctypes.MapToRequest(rpctypes.JSONRPCIntID(id), "subscribe", map[string]interface{}{"query": query})
+	if err != nil {
+		return fmt.Errorf("failed to create subscribe request: %w", err)
+	}
+	if err := s.write(websocket.TextMessage, req); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", query, err)
+	}
+	return nil
+}
+
+// Events returns the channel receiving the events of all the subscriptions.
+func (s *eventStream) Events() <-chan ctypes.ResultEvent {
+	return s.events
+}
+
+// Done is closed when the stream stops receiving events.
+func (s *eventStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the reason why the stream is done.
+func (s *eventStream) Err() error {
+	<-s.done
+	return s.err
+}
+
+func (s *eventStream) Close() {
+	s.closeWithError(fmt.Errorf("stream closed"))
+}
+
+func (s *eventStream) closeWithError(err error) {
+	s.closeOnce.Do(func() {
+		s.err = err
+		close(s.done)
+		s.conn.Close()
+	})
+}
+
+func (s *eventStream) write(messageType int, v interface{}) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait)); err != nil {
+		return err
+	}
+	if messageType == websocket.PingMessage {
+		return s.conn.WriteMessage(messageType, nil)
+	}
+	return s.conn.WriteJSON(v)
+}
+
+func (s *eventStream) readLoop() {
+	s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var resp rpctypes.RPCResponse
+		if err := s.conn.ReadJSON(&resp); err != nil {
+			s.closeWithError(fmt.Errorf("failed to read websocket: %w", err))
+			return
+		}
+		s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		if resp.Error != nil {
+			s.closeWithError(fmt.Errorf("subscription error: %w", resp.Error))
+			return
+		}
+
+		event, err := decodeEvent(resp.Result)
+		if err != nil {
+			s.closeWithError(err)
+			return
+		}
+		if event == nil {
+			// Subscription acknowledgment
+			continue
+		}
+
+		select {
+		case s.events <- *event:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *eventStream) pingLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.write(websocket.PingMessage, nil); err != nil {
+				s.closeWithError(fmt.Errorf("failed to ping websocket: %w", err))
+				return
+			}
+		}
+	}
+}
+
+// decodeEvent decodes the result of a subscription message. It returns nil
+// for the empty results acknowledging subscriptions.
+func decodeEvent(result json.RawMessage) (*ctypes.ResultEvent, error) {
+	event := &ctypes.ResultEvent{}
+	if err := cmtjson.Unmarshal(result, event); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+	if event.Query == "" {
+		return nil, nil
+	}
+	return event, nil
+}
+
+// websocketURL returns the websocket URL of an RPC endpoint. Credentials are
+// moved to the Authorization header since they are not allowed in websocket URLs.
+func websocketURL(endpoint string) (string, http.Header, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse endpoint: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "tcp", "ws":
+		u.Scheme = "ws"
+	case "https", "wss":
+		u.Scheme = "wss"
+	default:
+		return "", nil, fmt.Errorf("unsupported scheme for websocket: %s", u.Scheme)
+	}
+
+	header := http.Header{}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		header.Set("Authorization", "Basic "+credentials)
+		u.User = nil
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/websocket"
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String(), header, nil
+}
@@ -2,12 +2,16 @@ package watcher
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 	"github.com/cometbft/cometbft/types"
 	"github.com/fatih/color"
@@ -24,25 +28,53 @@ type BlockWebhook struct {
 }
 
 type BlockWatcher struct {
-	trackedValidators   []TrackedValidator
-	metrics             *metrics.Metrics
-	writer              io.Writer
-	blockChan           chan *BlockInfo
-	validatorSet        atomic.Value // []*types.Validator
-	latestBlockHeight   int64
-	latestBlockProposer string
-	webhook             *webhook.Webhook
-	customWebhooks      []BlockWebhook
+	trackedValidators    []TrackedValidator
+	trackedIndex         map[string][]int // raw address -> positions in trackedValidators
+	metrics              *metrics.Metrics
+	writer               io.Writer
+	blocks               chan poolBlock
+	validatorSet         atomic.Value // *indexedValidatorSet (latest)
+	validatorSets        *validatorSetCache
+	voteExtensionsHeight atomic.Int64 // height from which vote extensions are enabled (0 if disabled)
+	latestBlockHeight    int64
+	latestBlockProposer  string
+	latestBlockTime      time.Time
+	latestBlockTxs       int       // number of transactions of the latest block (-1 if unknown)
+	latestBlockMempool   int       // mempool size sampled while the latest block was proposed (-1 if unknown)
+	latestBlockReceived  time.Time // when the latest block was received
+	mempoolMu            sync.Mutex
+	mempool              mempoolSample // latest mempool size sampled by the node watcher
+	proposalWindows      map[string]*proposalWindow
+	divergences          *divergenceTracker
+	webhook              *webhook.Webhook
+	customWebhooks       []BlockWebhook
+	options              BlockWatcherOptions
 }
 
-func NewBlockWatcher(validators []TrackedValidator, metrics *metrics.Metrics, writer io.Writer, webhook *webhook.Webhook, customWebhooks []BlockWebhook) *BlockWatcher {
+// Max number of validators per page of the CometBFT validator set
+const validatorSetPageSize = 100
+
+type BlockWatcherOptions struct {
+	// Number of blocks over which expected & actual proposals are compared
+	ProposalWindow int
+
+	// When to sync the validator set & consensus params of each node (default: every minute)
+	ValidatorSetSchedule rpc.Schedule
+}
+
+func NewBlockWatcher(validators []TrackedValidator, metrics *metrics.Metrics, writer io.Writer, webhook *webhook.Webhook, customWebhooks []BlockWebhook, options BlockWatcherOptions) *BlockWatcher {
 	return &BlockWatcher{
 		trackedValidators: validators,
+		trackedIndex:      newTrackedIndex(validators),
 		metrics:           metrics,
 		writer:            writer,
-		blockChan:         make(chan *BlockInfo),
+		blocks:            make(chan poolBlock, 100),
+		proposalWindows:   make(map[string]*proposalWindow),
+		divergences:       newDivergenceTracker(100),
+		validatorSets:     newValidatorSetCache(100),
 		webhook:           webhook,
 		customWebhooks:    customWebhooks,
+		options:           options,
 	}
 }
 
@@ -51,8 +83,9 @@ func (w *BlockWatcher) Start(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			return nil
-		case block := <-w.blockChan:
-			w.handleBlockInfo(ctx, block)
+		case b := <-w.blocks:
+			blockInfo := w.handleNodeBlock(ctx, b.node, b.block)
+			w.handleBlockInfo(ctx, blockInfo)
 		}
 	}
 }
@@ -62,109 +95,355 @@ func (w *BlockWatcher) OnNodeStart(ctx context.Context, node *rpc.Node) error {
 		return fmt.Errorf("failed to sync validator set: %w", err)
 	}
 
-	blockResp, err := node.Client.Block(ctx, nil)
-	if err != nil {
+	if err := w.syncConsensusParams(ctx, node); err != nil {
 		log.Warn().Err(err).
 			Str("node", node.Redacted()).
-			Msg("failed to get latest block")
-	} else {
-		w.handleNodeBlock(node, blockResp.Block)
+			Msg("failed to sync consensus params")
 	}
 
-	// Ticker to sync validator set
-	ticker := time.NewTicker(time.Minute)
+	// Periodically sync validator set
+	schedule := w.options.ValidatorSetSchedule.WithDefaultInterval(time.Minute)
 
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				log.Debug().Err(ctx.Err()).Str("node", node.Redacted()).Msgf("stopping block watcher loop")
-				return
-			case <-ticker.C:
-				if err := w.syncValidatorSet(ctx, node); err != nil {
-					log.Error().Err(err).Str("node", node.Redacted()).Msg("failed to sync validator set")
-				}
+		schedule.Loop(ctx, node.Height, func() {
+			if err := w.syncValidatorSet(ctx, node); err != nil {
+				log.Error().Err(err).Str("node", node.Redacted()).Msg("failed to sync validator set")
 			}
-		}
+			if err := w.syncConsensusParams(ctx, node); err != nil {
+				log.Error().Err(err).Str("node", node.Redacted()).Msg("failed to sync consensus params")
+			}
+		})
+		log.Debug().Err(ctx.Err()).Str("node", node.Redacted()).Msgf("stopping block watcher loop")
 	}()
 
 	return nil
 }
 
-func (w *BlockWatcher) OnNewBlock(ctx context.Context, node *rpc.Node, evt *ctypes.ResultEvent) error {
-	// Ignore blocks if node is catching up
-	if !node.IsSynced() {
-		return nil
-	}
-
-	blockEvent := evt.Data.(types.EventDataNewBlock)
-	block := blockEvent.Block
+// OnNodeBlock is called by the pool for the blocks of every node.
+func (w *BlockWatcher) OnNodeBlock(ctx context.Context, node *rpc.Node, block *types.Block) {
+	// Set node block height
+	w.metrics.NodeBlockHeight.WithLabelValues(node.ChainID(), node.Endpoint()).Set(float64(block.Height))
 
-	w.handleNodeBlock(node, block)
+	// Compare the block with the ones received from other nodes
+	w.checkDivergence(node, block)
+}
 
-	return nil
+// OnBlock is called by the pool once for each new block. Blocks are queued
+// to be handled by Start, as handling them requires RPC calls which must not
+// delay the blocks of the other nodes.
+func (w *BlockWatcher) OnBlock(ctx context.Context, node *rpc.Node, block *types.Block) {
+	select {
+	case w.blocks <- poolBlock{node: node, block: block}:
+	default:
+		log.Warn().Str("node", node.Redacted()).Msgf("block queue is full, dropping block %d", block.Height)
+		w.metrics.DroppedBlocks.WithLabelValues(node.ChainID()).Inc()
+	}
 }
 
+// OnValidatorSetUpdates refreshes the latest validator set when it changes.
+// Blocks are evaluated against the set of their own height, fetched again as
+// soon as the validators hash of the headers changes.
 func (w *BlockWatcher) OnValidatorSetUpdates(ctx context.Context, node *rpc.Node, evt *ctypes.ResultEvent) error {
 	// Ignore blocks if node is catching up
 	if !node.IsSynced() {
 		return nil
 	}
 
-	w.syncValidatorSet(ctx, node)
+	if err := w.syncValidatorSet(ctx, node); err != nil {
+		return fmt.Errorf("failed to sync validator set: %w", err)
+	}
 
 	return nil
 }
 
-func (w *BlockWatcher) handleNodeBlock(node *rpc.Node, block *types.Block) {
-	validatorSet := w.getValidatorSet()
+func (w *BlockWatcher) handleNodeBlock(ctx context.Context, node *rpc.Node, block *types.Block) *BlockInfo {
+	// Light blocks don't include the transactions, needed to decode the vote
+	// extensions of the tracked validators
+	if rpc.IsLightBlock(block) && w.voteExtensionsEnabled(block.Height) && w.tracksCommit(block) {
+		blockResp, err := node.Client.Block(ctx, &block.Height)
+		if err != nil {
+			log.Warn().Err(err).
+				Str("node", node.Redacted()).
+				Msgf("failed to get full block at height %d", block.Height)
+		} else {
+			block = blockResp.Block
+		}
+	}
 
-	if len(validatorSet) != block.LastCommit.Size() {
-		log.Warn().Msgf("validator set size mismatch: %d vs %d", len(validatorSet), block.LastCommit.Size())
+	// The last commit is signed by the validator set of the previous height
+	w.validatorSets.AddHeader(&block.Header)
+	validatorSet, err := w.validatorSetAt(ctx, node, block.Height-1)
+	if err != nil {
+		log.Warn().Err(err).
+			Str("node", node.Redacted()).
+			Msgf("failed to get validator set at height %d, using latest one", block.Height-1)
+		validatorSet = w.getValidatorSet()
 	}
 
-	// Set node block height
-	w.metrics.NodeBlockHeight.WithLabelValues(node.ChainID(), node.Endpoint()).Set(float64(block.Height))
+	if validatorSet.Size() != block.LastCommit.Size() {
+		log.Warn().Msgf("validator set size mismatch: %d vs %d", validatorSet.Size(), block.LastCommit.Size())
+	}
 
 	// Extract block info
-	w.blockChan <- NewBlockInfo(block, w.computeValidatorStatus(block))
+	extendedCommit := extractExtendedCommitInfo(block, w.voteExtensionsHeight.Load())
+	blockInfo := NewBlockInfo(block, w.computeValidatorStatus(block, validatorSet, extendedCommit))
+	blockInfo.VoteExtensions = extendedCommit != nil
+	if !blockInfo.VoteExtensions && w.voteExtensionsEnabled(block.Height) && !rpc.IsLightBlock(block) {
+		// Vote extensions aren't counted (neither included nor missed) for
+		// blocks not following the first transaction convention
+		log.Debug().Msgf("no extended commit info in the first transaction of block %d", block.Height)
+	}
+	if rpc.IsLightBlock(block) {
+		// Transaction count read from the block meta when the block was polled
+		blockInfo.Transactions = -1
+		if numTxs, ok := node.NumTxs(block.Height); ok {
+			blockInfo.Transactions = numTxs
+		}
+	}
+	for _, val := range validatorSet.Validators {
+		blockInfo.TotalVotingPower += val.VotingPower
+	}
+
+	// The previous block has been committed after round 0: find out which
+	// validator was expected to propose it at round 0.
+	if block.LastCommit.Round > 0 && block.Height > 1 {
+		height := block.Height - 1
+		validatorSet, err := w.validatorSetWithPriorities(ctx, node, height)
+		if err != nil {
+			log.Warn().Err(err).
+				Str("node", node.Redacted()).
+				Msgf("failed to get validator set at height %d", height)
+		} else {
+			blockInfo.ExpectedProposerAddress = expectedProposer(validatorSet.Validators)
+		}
+	}
+
+	blockInfo.MempoolTransactions = w.proposalMempool(block.Height, w.latestBlockReceived)
+	w.latestBlockReceived = time.Now()
+
+	return blockInfo
+}
+
+// OnNodeMempool records the mempool size sampled by the node watcher, used to
+// tell whether the proposer of an empty block left transactions pending.
+func (w *BlockWatcher) OnNodeMempool(ctx context.Context, node *rpc.Node, mempool *ctypes.ResultUnconfirmedTxs) {
+	if !node.IsSynced() {
+		return
+	}
+
+	w.mempoolMu.Lock()
+	defer w.mempoolMu.Unlock()
+
+	w.mempool = mempoolSample{Total: mempool.Total, Time: time.Now()}
+}
+
+// proposalMempool returns the latest mempool size sampled since the previous
+// block was received, ie. while the block at the given height was proposed
+// (-1 if unknown or if blocks were skipped).
+func (w *BlockWatcher) proposalMempool(height int64, previousBlockReceived time.Time) int {
+	w.mempoolMu.Lock()
+	sample := w.mempool
+	w.mempoolMu.Unlock()
+
+	if height != w.latestBlockHeight+1 || previousBlockReceived.IsZero() || sample.Time.Before(previousBlockReceived) {
+		return -1
+	}
+	return sample.Total
+}
+
+// tracksCommit returns true when a tracked validator is part of the last
+// commit of the block.
+func (w *BlockWatcher) tracksCommit(block *types.Block) bool {
+	for _, sig := range block.LastCommit.Signatures {
+		if _, ok := w.trackedIndex[string(sig.ValidatorAddress)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// voteExtensionsEnabled returns true when the block at the given height
+// includes vote extensions (injected in its first transaction).
+func (w *BlockWatcher) voteExtensionsEnabled(height int64) bool {
+	voteExtensionsHeight := w.voteExtensionsHeight.Load()
+	return voteExtensionsHeight > 0 && height-1 >= voteExtensionsHeight
+}
+
+func (w *BlockWatcher) checkDivergence(node *rpc.Node, block *types.Block) {
+	hashes := newBlockHashes(node.Redacted(), block)
+
+	expected := w.divergences.Add(block.Height, hashes)
+	if expected == nil {
+		return
+	}
+
+	fields := hashes.Diff(*expected)
+	for _, field := range fields {
+		w.metrics.NodeDivergences.WithLabelValues(block.ChainID, node.Endpoint(), field).Inc()
+	}
+
+	log.Error().
+		Str("node", node.Redacted()).
+		Int64("height", block.Height).
+		Strs("hashes", fields).
+		Msgf("node diverges from %s (block hash %s vs %s, app hash %s vs %s)",
+			expected.Node, hashes.BlockHash, expected.BlockHash, hashes.AppHash, expected.AppHash)
+
+	if w.webhook != nil {
+		w.triggerDivergenceWebhook(block.ChainID, block.Height, node, hashes, *expected)
+	}
+}
+
+func (w *BlockWatcher) triggerDivergenceWebhook(chainID string, height int64, node *rpc.Node, hashes, expected blockHashes) {
+	msg := struct {
+		Type                    string `json:"type"`
+		Block                   int64  `json:"block"`
+		ChainID                 string `json:"chain_id"`
+		Node                    string `json:"node"`
+		BlockHash               string `json:"block_hash"`
+		AppHash                 string `json:"app_hash"`
+		LastResultsHash         string `json:"last_results_hash"`
+		ExpectedNode            string `json:"expected_node"`
+		ExpectedBlockHash       string `json:"expected_block_hash"`
+		ExpectedAppHash         string `json:"expected_app_hash"`
+		ExpectedLastResultsHash string `json:"expected_last_results_hash"`
+	}{
+		Type:                    "divergence",
+		Block:                   height,
+		ChainID:                 chainID,
+		Node:                    node.Redacted(),
+		BlockHash:               hashes.BlockHash,
+		AppHash:                 hashes.AppHash,
+		LastResultsHash:         hashes.LastResultsHash,
+		ExpectedNode:            expected.Node,
+		ExpectedBlockHash:       expected.BlockHash,
+		ExpectedAppHash:         expected.AppHash,
+		ExpectedLastResultsHash: expected.LastResultsHash,
+	}
+
+	go func() {
+		if err := w.webhook.Send(context.Background(), msg); err != nil {
+			log.Error().Err(err).Msg("failed to send divergence webhook")
+		}
+	}()
 }
 
-func (w *BlockWatcher) getValidatorSet() []*types.Validator {
+func (w *BlockWatcher) getValidatorSet() *indexedValidatorSet {
 	validatorSet := w.validatorSet.Load()
 	if validatorSet == nil {
 		return nil
 	}
 
-	return validatorSet.([]*types.Validator)
+	return validatorSet.(*indexedValidatorSet)
 }
 
 func (w *BlockWatcher) syncValidatorSet(ctx context.Context, n *rpc.Node) error {
-	validators := make([]*types.Validator, 0)
+	validators, height, err := w.fetchValidatorSet(ctx, n, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("node", n.Redacted()).
+		Int("validators", len(validators)).
+		Msgf("validator set")
+
+	w.metrics.ValidatorSet.WithLabelValues(n.ChainID(), "consensus").Set(float64(len(validators)))
+
+	validatorSet := newIndexedValidatorSet(validators)
+	w.validatorSet.Store(validatorSet)
+	if height > 0 {
+		w.validatorSets.Add(height, validatorSet)
+	}
+
+	for _, tracked := range w.trackedValidators {
+		if val := validatorSet.Get(tracked.Address); val != nil {
+			w.metrics.ProposerPriority.WithLabelValues(n.ChainID(), tracked.Address, tracked.Name).Set(float64(val.ProposerPriority))
+		}
+	}
+
+	return nil
+}
 
-	for i := 0; i < 5; i++ {
-		var (
-			page    int = i + 1
-			perPage int = 100
-		)
+// validatorSetAt returns the validator set at the given height, from the
+// cache when possible.
+func (w *BlockWatcher) validatorSetAt(ctx context.Context, n *rpc.Node, height int64) (*indexedValidatorSet, error) {
+	if validatorSet := w.validatorSets.Get(height); validatorSet != nil {
+		return validatorSet, nil
+	}
 
-		result, err := n.Client.Validators(ctx, nil, &page, &perPage)
+	validators, _, err := w.fetchValidatorSet(ctx, n, &height)
+	if err != nil {
+		return nil, err
+	}
+	validatorSet := newIndexedValidatorSet(validators)
+	w.validatorSets.Add(height, validatorSet)
+
+	return validatorSet, nil
+}
+
+// validatorSetWithPriorities returns the validator set fetched at the given
+// height, whose proposer priorities are the ones of round 0 at that height.
+// Unlike validatorSetAt, sets fetched at other heights are never reused.
+func (w *BlockWatcher) validatorSetWithPriorities(ctx context.Context, n *rpc.Node, height int64) (*indexedValidatorSet, error) {
+	if validatorSet := w.validatorSets.GetFetched(height); validatorSet != nil {
+		return validatorSet, nil
+	}
+
+	validators, _, err := w.fetchValidatorSet(ctx, n, &height)
+	if err != nil {
+		return nil, err
+	}
+	validatorSet := newIndexedValidatorSet(validators)
+	w.validatorSets.Add(height, validatorSet)
+
+	return validatorSet, nil
+}
+
+// fetchValidatorSet returns the CometBFT validator set at the given height
+// (latest height if nil) along with the height of the set. All the pages are
+// fetched at the height of the first one.
+func (w *BlockWatcher) fetchValidatorSet(ctx context.Context, n *rpc.Node, height *int64) ([]*types.Validator, int64, error) {
+	var (
+		validators  []*types.Validator
+		blockHeight int64
+	)
+
+	for page := 1; ; page++ {
+		perPage := validatorSetPageSize
+
+		result, err := n.Client.Validators(ctx, height, &page, &perPage)
 		if err != nil {
-			return fmt.Errorf("failed to get validators: %w", err)
+			return nil, 0, fmt.Errorf("failed to get validators: %w", err)
+		}
+		if validators == nil {
+			validators = make([]*types.Validator, 0, result.Total)
 		}
 		validators = append(validators, result.Validators...)
 
-		if len(validators) >= int(result.Total) {
+		if blockHeight == 0 && result.BlockHeight > 0 {
+			blockHeight = result.BlockHeight
+			height = &blockHeight
+		}
+
+		if len(validators) >= result.Total {
 			break
 		}
+		if len(result.Validators) == 0 {
+			return nil, 0, fmt.Errorf("failed to get validators: got %d out of %d", len(validators), result.Total)
+		}
 	}
 
-	log.Debug().
-		Str("node", n.Redacted()).
-		Int("validators", len(validators)).
-		Msgf("validator set")
+	return validators, blockHeight, nil
+}
+
+func (w *BlockWatcher) syncConsensusParams(ctx context.Context, n *rpc.Node) error {
+	result, err := n.Client.ConsensusParams(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get consensus params: %w", err)
+	}
 
-	w.validatorSet.Store(validators)
+	w.voteExtensionsHeight.Store(result.ConsensusParams.ABCI.VoteExtensionsEnableHeight)
 
 	return nil
 }
@@ -183,7 +462,16 @@ func (w *BlockWatcher) handleBlockInfo(ctx context.Context, block *BlockInfo) {
 		w.metrics.MissedBlocks.WithLabelValues(chainId, val.Address, val.Name)
 		w.metrics.SoloMissedBlocks.WithLabelValues(chainId, val.Address, val.Name)
 		w.metrics.ConsecutiveMissedBlocks.WithLabelValues(chainId, val.Address, val.Name)
+		w.metrics.MissedProposals.WithLabelValues(chainId, val.Address, val.Name)
+		w.metrics.EmptyProposedBlocks.WithLabelValues(chainId, val.Address, val.Name)
+		w.metrics.LateProposedBlocks.WithLabelValues(chainId, val.Address, val.Name)
+		w.metrics.IncludedVoteExtensions.WithLabelValues(chainId, val.Address, val.Name)
+		w.metrics.MissedVoteExtensions.WithLabelValues(chainId, val.Address, val.Name)
+		for _, flag := range []string{"commit", "nil", "absent"} {
+			w.metrics.Signatures.WithLabelValues(chainId, val.Address, val.Name, flag)
+		}
 	}
+	w.metrics.DroppedBlocks.WithLabelValues(chainId)
 	w.metrics.SkippedBlocks.WithLabelValues(chainId)
 
 	blockDiff := block.Height - w.latestBlockHeight
@@ -195,14 +483,63 @@ func (w *BlockWatcher) handleBlockInfo(ctx context.Context, block *BlockInfo) {
 	w.metrics.BlockHeight.WithLabelValues(chainId).Set(float64(block.Height))
 	w.metrics.ActiveSet.WithLabelValues(chainId).Set(float64(block.TotalValidators))
 	w.metrics.TrackedBlocks.WithLabelValues(chainId).Inc()
-	w.metrics.Transactions.WithLabelValues(chainId).Add(float64(block.Transactions))
+	if block.Transactions >= 0 {
+		w.metrics.Transactions.WithLabelValues(chainId).Add(float64(block.Transactions))
+	}
 
 	// Print block result & update metrics
 	validatorStatus := []string{}
 	for _, res := range block.ValidatorStatus {
+		// Precommit latency is measured against the time of the block being signed
+		if res.Signed && blockDiff == 1 && !w.latestBlockTime.IsZero() && !res.Timestamp.IsZero() {
+			latency := res.Timestamp.Sub(w.latestBlockTime)
+			w.metrics.PrecommitLatency.WithLabelValues(block.ChainID, res.Address, res.Label).Observe(latency.Seconds())
+		}
+
+		if res.Bonded {
+			w.metrics.Signatures.WithLabelValues(block.ChainID, res.Address, res.Label, res.SignatureFlag()).Inc()
+		}
+
+		if res.Bonded && block.VoteExtensions {
+			if res.VoteExtension {
+				w.metrics.IncludedVoteExtensions.WithLabelValues(block.ChainID, res.Address, res.Label).Inc()
+			} else {
+				w.metrics.MissedVoteExtensions.WithLabelValues(block.ChainID, res.Address, res.Label).Inc()
+			}
+		}
+
+		// Compare proposed blocks with the share of voting power over the window
+		if blockDiff == 1 && block.TotalVotingPower > 0 {
+			expected := float64(res.VotingPower) / float64(block.TotalVotingPower)
+			window := w.getProposalWindow(res.Address)
+			window.Add(expected, w.latestBlockProposer == res.Address)
+			w.metrics.ExpectedProposedBlocks.WithLabelValues(block.ChainID, res.Address, res.Label).Set(window.Expected())
+			w.metrics.WindowProposedBlocks.WithLabelValues(block.ChainID, res.Address, res.Label).Set(float64(window.Proposed()))
+		}
+
+		// Validator was expected to propose at round 0 but the block was committed at a later round
+		if blockDiff == 1 && block.CommitRound > 0 && block.ExpectedProposerAddress == res.Address && w.latestBlockProposer != res.Address {
+			log.Warn().Msgf("validator %s missed its proposal at height %d (committed at round %d)", res.Label, block.Height-1, block.CommitRound)
+			w.metrics.MissedProposals.WithLabelValues(block.ChainID, res.Address, res.Label).Inc()
+		}
+
 		icon := "⚪️"
 		if w.latestBlockProposer == res.Address {
 			icon = "👑"
+			log.Debug().
+				Str("validator", res.Label).
+				Int64("height", block.Height-1).
+				Int32("round", block.CommitRound).
+				Int("txs", w.latestBlockTxs).
+				Msg("proposed block")
+			// Blocks with an unknown number of transactions are not considered empty
+			if w.latestBlockTxs == 0 && w.latestBlockMempool > 0 {
+				w.metrics.EmptyProposedBlocks.WithLabelValues(block.ChainID, res.Address, res.Label).Inc()
+			}
+			// The commit round describes the previous block only when no block was skipped
+			if blockDiff == 1 && block.CommitRound > 0 {
+				w.metrics.LateProposedBlocks.WithLabelValues(block.ChainID, res.Address, res.Label).Inc()
+			}
 			w.metrics.ProposedBlocks.WithLabelValues(block.ChainID, res.Address, res.Label).Inc()
 			w.metrics.ValidatedBlocks.WithLabelValues(block.ChainID, res.Address, res.Label).Inc()
 			w.metrics.ConsecutiveMissedBlocks.WithLabelValues(block.ChainID, res.Address, res.Label).Set(0)
@@ -212,6 +549,9 @@ func (w *BlockWatcher) handleBlockInfo(ctx context.Context, block *BlockInfo) {
 			w.metrics.ConsecutiveMissedBlocks.WithLabelValues(block.ChainID, res.Address, res.Label).Set(0)
 		} else if res.Bonded {
 			icon = "❌"
+			if res.Flag == types.BlockIDFlagNil {
+				icon = "🟡"
+			}
 			w.metrics.MissedBlocks.WithLabelValues(block.ChainID, res.Address, res.Label).Inc()
 			w.metrics.ConsecutiveMissedBlocks.WithLabelValues(block.ChainID, res.Address, res.Label).Inc()
 
@@ -235,44 +575,73 @@ func (w *BlockWatcher) handleBlockInfo(ctx context.Context, block *BlockInfo) {
 
 	w.latestBlockHeight = block.Height
 	w.latestBlockProposer = block.ProposerAddress
+	w.latestBlockTime = block.Time
+	w.latestBlockTxs = block.Transactions
+	w.latestBlockMempool = block.MempoolTransactions
 }
 
-func (w *BlockWatcher) computeValidatorStatus(block *types.Block) []ValidatorStatus {
-	validatorStatus := []ValidatorStatus{}
+func (w *BlockWatcher) computeValidatorStatus(block *types.Block, validatorSet *indexedValidatorSet, extendedCommit *abci.ExtendedCommitInfo) []ValidatorStatus {
+	validatorStatus := make([]ValidatorStatus, len(w.trackedValidators))
 
-	for _, val := range w.trackedValidators {
-		bonded := w.isValidatorActive(val.Address)
-		signed := false
-		rank := 0
-		for i, sig := range block.LastCommit.Signatures {
-			if val.Address == sig.ValidatorAddress.String() {
-				bonded = true
-				signed = (sig.BlockIDFlag == types.BlockIDFlagCommit)
-				rank = i + 1
+	for i, val := range w.trackedValidators {
+		status := ValidatorStatus{
+			Address: val.Address,
+			Label:   val.Name,
+			Flag:    types.BlockIDFlagAbsent,
+		}
+		if validator := validatorSet.Get(val.Address); validator != nil {
+			status.Bonded = true
+			status.VotingPower = validator.VotingPower
+		}
+		validatorStatus[i] = status
+	}
+
+	// Signatures & votes are scanned once, looking up tracked validators by address
+	for i, sig := range block.LastCommit.Signatures {
+		for _, j := range w.trackedIndex[string(sig.ValidatorAddress)] {
+			status := &validatorStatus[j]
+			if status.Signed {
+				continue
 			}
-			if signed {
-				break
+			status.Bonded = true
+			status.Signed = (sig.BlockIDFlag == types.BlockIDFlagCommit)
+			status.Rank = i + 1
+			status.Flag = sig.BlockIDFlag
+			status.Timestamp = sig.Timestamp
+		}
+	}
+	if extendedCommit != nil {
+		for _, vote := range extendedCommit.Votes {
+			for _, j := range w.trackedIndex[string(vote.Validator.Address)] {
+				validatorStatus[j].VoteExtension = vote.BlockIdFlag == cmtproto.BlockIDFlagCommit && len(vote.VoteExtension) > 0
 			}
 		}
-		validatorStatus = append(validatorStatus, ValidatorStatus{
-			Address: val.Address,
-			Label:   val.Name,
-			Bonded:  bonded,
-			Signed:  signed,
-			Rank:    rank,
-		})
 	}
 
 	return validatorStatus
 }
 
-func (w *BlockWatcher) isValidatorActive(address string) bool {
-	for _, val := range w.getValidatorSet() {
-		if val.Address.String() == address {
-			return true
+// newTrackedIndex maps the raw consensus addresses of the tracked validators
+// to their positions in the list (the same address may be tracked twice).
+func newTrackedIndex(validators []TrackedValidator) map[string][]int {
+	index := make(map[string][]int, len(validators))
+	for i, val := range validators {
+		address, err := hex.DecodeString(val.Address)
+		if err != nil || len(address) == 0 {
+			continue
 		}
+		index[string(address)] = append(index[string(address)], i)
 	}
-	return false
+	return index
+}
+
+func (w *BlockWatcher) getProposalWindow(address string) *proposalWindow {
+	window, ok := w.proposalWindows[address]
+	if !ok {
+		window = newProposalWindow(w.options.ProposalWindow)
+		w.proposalWindows[address] = window
+	}
+	return window
 }
 
 func (w *BlockWatcher) handleWebhooks(ctx context.Context, block *BlockInfo) {
@@ -3,13 +3,29 @@ package watcher
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cometbft/cometbft/rpc/client/http"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
 	"github.com/kilnfi/cosmos-validator-watcher/pkg/metrics"
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/rpc"
 	"github.com/kilnfi/cosmos-validator-watcher/pkg/webhook"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"gotest.tools/assert"
 )
 
@@ -31,13 +47,19 @@ func TestBlockWatcher(t *testing.T) {
 		&bytes.Buffer{},
 		webhook.New(url.URL{}),
 		[]BlockWebhook{},
+		BlockWatcherOptions{ProposalWindow: 3},
 	)
 
 	t.Run("Handle BlockInfo", func(t *testing.T) {
+		blockTime := func(height int64) time.Time {
+			return time.Unix(1700000000+height*6, 0)
+		}
+
 		blocks := []BlockInfo{
 			{
 				ChainID:          chainID,
 				Height:           36,
+				Time:             blockTime(36),
 				Transactions:     4,
 				TotalValidators:  1,
 				SignedValidators: 0,
@@ -54,6 +76,7 @@ func TestBlockWatcher(t *testing.T) {
 			{
 				ChainID:          chainID,
 				Height:           41,
+				Time:             blockTime(41),
 				Transactions:     5,
 				TotalValidators:  1,
 				SignedValidators: 0,
@@ -70,49 +93,55 @@ func TestBlockWatcher(t *testing.T) {
 			{
 				ChainID:          chainID,
 				Height:           42,
+				Time:             blockTime(42),
 				Transactions:     6,
 				TotalValidators:  2,
 				SignedValidators: 1,
 				ValidatorStatus: []ValidatorStatus{
 					{
-						Address: kilnAddress,
-						Label:   kilnName,
-						Bonded:  true,
-						Signed:  true,
-						Rank:    2,
+						Address:   kilnAddress,
+						Label:     kilnName,
+						Bonded:    true,
+						Signed:    true,
+						Rank:      2,
+						Timestamp: blockTime(41).Add(500 * time.Millisecond),
 					},
 				},
 			},
 			{
 				ChainID:          chainID,
 				Height:           43,
+				Time:             blockTime(43),
 				Transactions:     7,
 				TotalValidators:  2,
 				SignedValidators: 2,
 				ProposerAddress:  kilnAddress,
 				ValidatorStatus: []ValidatorStatus{
 					{
-						Address: kilnAddress,
-						Label:   kilnName,
-						Bonded:  true,
-						Signed:  true,
-						Rank:    2,
+						Address:   kilnAddress,
+						Label:     kilnName,
+						Bonded:    true,
+						Signed:    true,
+						Rank:      2,
+						Timestamp: blockTime(42).Add(time.Second),
 					},
 				},
 			},
 			{
 				ChainID:          chainID,
 				Height:           44,
+				Time:             blockTime(44),
 				Transactions:     7,
 				TotalValidators:  2,
 				SignedValidators: 2,
 				ValidatorStatus: []ValidatorStatus{
 					{
-						Address: kilnAddress,
-						Label:   kilnName,
-						Bonded:  true,
-						Signed:  true,
-						Rank:    2,
+						Address:   kilnAddress,
+						Label:     kilnName,
+						Bonded:    true,
+						Signed:    true,
+						Rank:      2,
+						Timestamp: blockTime(43).Add(1500 * time.Millisecond),
 					},
 				},
 			},
@@ -148,5 +177,665 @@ func TestBlockWatcher(t *testing.T) {
 		assert.Equal(t, float64(1), testutil.ToFloat64(blockWatcher.metrics.MissedBlocks.WithLabelValues(chainID, kilnAddress, kilnName)))
 		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.SoloMissedBlocks.WithLabelValues(chainID, kilnAddress, kilnName)))
 		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.ConsecutiveMissedBlocks.WithLabelValues(chainID, kilnAddress, kilnName)))
+
+		latency := &dto.Metric{}
+		err := blockWatcher.metrics.PrecommitLatency.WithLabelValues(chainID, kilnAddress, kilnName).(prometheus.Histogram).Write(latency)
+		assert.NilError(t, err)
+		assert.Equal(t, uint64(3), latency.GetHistogram().GetSampleCount())
+		assert.Equal(t, float64(3), latency.GetHistogram().GetSampleSum())
+	})
+	t.Run("Handle Nil Votes", func(t *testing.T) {
+		blockWatcher.writer.(*bytes.Buffer).Reset()
+
+		blockWatcher.handleBlockInfo(context.Background(), &BlockInfo{
+			ChainID:          chainID,
+			Height:           45,
+			Transactions:     0,
+			TotalValidators:  2,
+			SignedValidators: 1,
+			ValidatorStatus: []ValidatorStatus{
+				{
+					Address: kilnAddress,
+					Label:   kilnName,
+					Bonded:  true,
+					Signed:  false,
+					Rank:    2,
+					Flag:    types.BlockIDFlagNil,
+				},
+			},
+		})
+
+		assert.Equal(t, "#44   1/2 validators 🟡 Kiln\n", blockWatcher.writer.(*bytes.Buffer).String())
+
+		assert.Equal(t, float64(2), testutil.ToFloat64(blockWatcher.metrics.MissedBlocks.WithLabelValues(chainID, kilnAddress, kilnName)))
+		assert.Equal(t, float64(1), testutil.ToFloat64(blockWatcher.metrics.ConsecutiveMissedBlocks.WithLabelValues(chainID, kilnAddress, kilnName)))
+		assert.Equal(t, float64(3), testutil.ToFloat64(blockWatcher.metrics.Signatures.WithLabelValues(chainID, kilnAddress, kilnName, "commit")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(blockWatcher.metrics.Signatures.WithLabelValues(chainID, kilnAddress, kilnName, "nil")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(blockWatcher.metrics.Signatures.WithLabelValues(chainID, kilnAddress, kilnName, "absent")))
+	})
+
+	t.Run("Handle Missed Proposal", func(t *testing.T) {
+		blockWatcher.writer.(*bytes.Buffer).Reset()
+
+		for _, height := range []int64{46, 47} {
+			blockWatcher.handleBlockInfo(context.Background(), &BlockInfo{
+				ChainID:                 chainID,
+				Height:                  height,
+				TotalValidators:         2,
+				SignedValidators:        2,
+				TotalVotingPower:        100,
+				CommitRound:             1,
+				ExpectedProposerAddress: kilnAddress,
+				ValidatorStatus: []ValidatorStatus{
+					{
+						Address:     kilnAddress,
+						Label:       kilnName,
+						Bonded:      true,
+						Signed:      true,
+						Rank:        2,
+						VotingPower: 25,
+					},
+				},
+			})
+		}
+
+		assert.Equal(t, float64(2), testutil.ToFloat64(blockWatcher.metrics.MissedProposals.WithLabelValues(chainID, kilnAddress, kilnName)))
+		assert.Equal(t, float64(0.5), testutil.ToFloat64(blockWatcher.metrics.ExpectedProposedBlocks.WithLabelValues(chainID, kilnAddress, kilnName)))
+		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.WindowProposedBlocks.WithLabelValues(chainID, kilnAddress, kilnName)))
+	})
+
+	t.Run("Ignore Proposals After Skipped Blocks", func(t *testing.T) {
+		blockWatcher := NewBlockWatcher(
+			[]TrackedValidator{{Address: kilnAddress, Name: kilnName}},
+			metrics.New("cosmos_validator_watcher"),
+			&bytes.Buffer{},
+			webhook.New(url.URL{}),
+			[]BlockWebhook{},
+			BlockWatcherOptions{},
+		)
+
+		// The proposer of the latest known block doesn't match the commit
+		// round of the blocks following skipped ones
+		blocks := []BlockInfo{
+			{Height: 10, ProposerAddress: "AAAA"},
+			{Height: 12, CommitRound: 1, ExpectedProposerAddress: kilnAddress, ProposerAddress: kilnAddress},
+			{Height: 14, CommitRound: 1},
+		}
+		for _, block := range blocks {
+			block.ChainID = chainID
+			block.ValidatorStatus = []ValidatorStatus{
+				{
+					Address: kilnAddress,
+					Label:   kilnName,
+					Bonded:  true,
+					Signed:  true,
+				},
+			}
+			blockWatcher.handleBlockInfo(context.Background(), &block)
+		}
+
+		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.MissedProposals.WithLabelValues(chainID, kilnAddress, kilnName)))
+		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.LateProposedBlocks.WithLabelValues(chainID, kilnAddress, kilnName)))
+	})
+
+	t.Run("Handle Empty & Late Proposals", func(t *testing.T) {
+		// Pending transactions are compared with the mempool sampled while the
+		// block was proposed
+		blocks := []BlockInfo{
+			{
+				ChainID:             chainID,
+				Height:              48,
+				Transactions:        0,
+				MempoolTransactions: 5,
+				TotalValidators:     2,
+				SignedValidators:    2,
+				ProposerAddress:     kilnAddress,
+			},
+			{
+				ChainID:             chainID,
+				Height:              49,
+				Transactions:        0,
+				MempoolTransactions: 0,
+				TotalValidators:     2,
+				SignedValidators:    2,
+				ProposerAddress:     kilnAddress,
+				CommitRound:         2,
+			},
+			{
+				ChainID:             chainID,
+				Height:              50,
+				Transactions:        3,
+				MempoolTransactions: 5,
+				TotalValidators:     2,
+				SignedValidators:    2,
+			},
+			// Unknown number of transactions: the block isn't considered empty
+			{
+				ChainID:             chainID,
+				Height:              51,
+				Transactions:        -1,
+				MempoolTransactions: 5,
+				TotalValidators:     2,
+				SignedValidators:    2,
+				ProposerAddress:     kilnAddress,
+			},
+			{
+				ChainID:          chainID,
+				Height:           52,
+				Transactions:     1,
+				TotalValidators:  2,
+				SignedValidators: 2,
+			},
+		}
+
+		transactions := testutil.ToFloat64(blockWatcher.metrics.Transactions.WithLabelValues(chainID))
+		for _, block := range blocks {
+			block.ValidatorStatus = []ValidatorStatus{
+				{
+					Address: kilnAddress,
+					Label:   kilnName,
+					Bonded:  true,
+					Signed:  true,
+					Rank:    2,
+				},
+			}
+			blockWatcher.handleBlockInfo(context.Background(), &block)
+		}
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(blockWatcher.metrics.EmptyProposedBlocks.WithLabelValues(chainID, kilnAddress, kilnName)))
+		assert.Equal(t, transactions+4, testutil.ToFloat64(blockWatcher.metrics.Transactions.WithLabelValues(chainID)))
+		assert.Equal(t, float64(1), testutil.ToFloat64(blockWatcher.metrics.LateProposedBlocks.WithLabelValues(chainID, kilnAddress, kilnName)))
+	})
+
+	t.Run("Sample Mempool Between Blocks", func(t *testing.T) {
+		blockWatcher := NewBlockWatcher(
+			[]TrackedValidator{},
+			metrics.New("cosmos_validator_watcher"),
+			&bytes.Buffer{},
+			nil,
+			[]BlockWebhook{},
+			BlockWatcherOptions{},
+		)
+		node := newSyncedNode(t, "node-a", 100)
+		blockWatcher.latestBlockHeight = 99
+		received := time.Now()
+
+		// No sample since the previous block was received
+		assert.Equal(t, -1, blockWatcher.proposalMempool(100, received))
+
+		blockWatcher.OnNodeMempool(context.Background(), node, &ctypes.ResultUnconfirmedTxs{Total: 5})
+		assert.Equal(t, 5, blockWatcher.proposalMempool(100, received))
+
+		// Blocks were skipped or the sample is older than the previous block
+		assert.Equal(t, -1, blockWatcher.proposalMempool(101, received))
+		assert.Equal(t, -1, blockWatcher.proposalMempool(100, time.Now().Add(time.Second)))
+	})
+
+	t.Run("Tracked Validators In Commit", func(t *testing.T) {
+		address, err := hex.DecodeString(kilnAddress)
+		assert.NilError(t, err)
+
+		block := &types.Block{LastCommit: &types.Commit{Signatures: []types.CommitSig{
+			{ValidatorAddress: []byte{0x01}},
+		}}}
+		assert.Assert(t, !blockWatcher.tracksCommit(block))
+
+		block.LastCommit.Signatures = append(block.LastCommit.Signatures, types.CommitSig{ValidatorAddress: address})
+		assert.Assert(t, blockWatcher.tracksCommit(block))
+	})
+
+	t.Run("Queue Blocks Without Blocking", func(t *testing.T) {
+		blockWatcher := NewBlockWatcher(
+			[]TrackedValidator{},
+			metrics.New("cosmos_validator_watcher"),
+			&bytes.Buffer{},
+			nil,
+			[]BlockWebhook{},
+			BlockWatcherOptions{},
+		)
+
+		node := newSyncedNode(t, "node", 150)
+
+		// Blocks aren't consumed: the queue is full after 100 blocks
+		for height := int64(1); height <= 150; height++ {
+			blockWatcher.OnBlock(context.Background(), node, &types.Block{Header: types.Header{Height: height}})
+		}
+		assert.Equal(t, 100, len(blockWatcher.blocks))
+		assert.Equal(t, float64(50), testutil.ToFloat64(blockWatcher.metrics.DroppedBlocks.WithLabelValues(node.ChainID())))
+	})
+
+	t.Run("Handle Divergences", func(t *testing.T) {
+		newNode := func(endpoint string) *rpc.Node {
+			client, err := http.New(endpoint, "/websocket")
+			assert.NilError(t, err)
+			return rpc.NewNode(client)
+		}
+		newBlock := func(appHash string) *types.Block {
+			return &types.Block{
+				Header: types.Header{
+					ChainID: chainID,
+					Height:  100,
+					AppHash: []byte(appHash),
+					// Required to compute the block hash
+					ValidatorsHash: []byte("validators"),
+				},
+				LastCommit: &types.Commit{},
+			}
+		}
+
+		node1 := newNode("http://node-1:26657")
+		node2 := newNode("http://node-2:26657")
+		node3 := newNode("http://node-3:26657")
+
+		blockWatcher.checkDivergence(node1, newBlock("state"))
+		blockWatcher.checkDivergence(node2, newBlock("state"))
+		blockWatcher.checkDivergence(node3, newBlock("corrupted"))
+
+		// Same block received again from the diverging node
+		blockWatcher.checkDivergence(node3, newBlock("corrupted"))
+
+		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.NodeDivergences.WithLabelValues(chainID, node2.Endpoint(), "app_hash")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(blockWatcher.metrics.NodeDivergences.WithLabelValues(chainID, node3.Endpoint(), "app_hash")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(blockWatcher.metrics.NodeDivergences.WithLabelValues(chainID, node3.Endpoint(), "block_hash")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.NodeDivergences.WithLabelValues(chainID, node3.Endpoint(), "last_results_hash")))
+	})
+}
+
+func TestExtractExtendedCommitInfo(t *testing.T) {
+	var (
+		kilnAddress  = "3DC4DD610817606AD4A8F9D762A068A81E8741E2"
+		otherAddress = "25445D0EB353E9050AB11EC6197D5DCB611986DB"
+	)
+
+	address := func(addr string) types.Address {
+		b, err := hex.DecodeString(addr)
+		assert.NilError(t, err)
+		return b
+	}
+
+	info := abci.ExtendedCommitInfo{
+		Round: 0,
+		Votes: []abci.ExtendedVoteInfo{
+			{
+				Validator:     abci.Validator{Address: address(kilnAddress), Power: 10},
+				VoteExtension: []byte("prices"),
+				BlockIdFlag:   cmtproto.BlockIDFlagCommit,
+			},
+			{
+				Validator:   abci.Validator{Address: address(otherAddress), Power: 10},
+				BlockIdFlag: cmtproto.BlockIDFlagCommit,
+			},
+		},
+	}
+	tx, err := info.Marshal()
+	assert.NilError(t, err)
+
+	block := &types.Block{
+		Header: types.Header{Height: 100},
+		Data:   types.Data{Txs: types.Txs{tx}},
+		LastCommit: &types.Commit{
+			Height: 99,
+			Signatures: []types.CommitSig{
+				{BlockIDFlag: types.BlockIDFlagCommit, ValidatorAddress: address(kilnAddress)},
+				{BlockIDFlag: types.BlockIDFlagCommit, ValidatorAddress: address(otherAddress)},
+			},
+		},
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		assert.Assert(t, extractExtendedCommitInfo(block, 0) == nil)
+		assert.Assert(t, extractExtendedCommitInfo(block, 100) == nil)
 	})
+
+	t.Run("Not An Extended Commit", func(t *testing.T) {
+		other := &types.Block{
+			Header:     block.Header,
+			Data:       types.Data{Txs: types.Txs{[]byte("not an extended commit")}},
+			LastCommit: block.LastCommit,
+		}
+		assert.Assert(t, extractExtendedCommitInfo(other, 1) == nil)
+
+		blockWatcher := NewBlockWatcher(
+			[]TrackedValidator{{Address: kilnAddress, Name: "Kiln"}},
+			metrics.New("cosmos_validator_watcher"),
+			&bytes.Buffer{},
+			nil,
+			[]BlockWebhook{},
+			BlockWatcherOptions{},
+		)
+		blockWatcher.voteExtensionsHeight.Store(1)
+
+		// Vote extensions are enabled but unknown: they are not counted as missed
+		blockInfo := NewBlockInfo(other, blockWatcher.computeValidatorStatus(other, nil, nil))
+		blockWatcher.handleBlockInfo(context.Background(), blockInfo)
+
+		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.IncludedVoteExtensions.WithLabelValues("", kilnAddress, "Kiln")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.MissedVoteExtensions.WithLabelValues("", kilnAddress, "Kiln")))
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		extendedCommit := extractExtendedCommitInfo(block, 99)
+		assert.Assert(t, extendedCommit != nil)
+
+		blockWatcher := NewBlockWatcher(
+			[]TrackedValidator{
+				{Address: kilnAddress, Name: "Kiln"},
+				{Address: otherAddress, Name: "Other"},
+			},
+			metrics.New("cosmos_validator_watcher"),
+			&bytes.Buffer{},
+			nil,
+			[]BlockWebhook{},
+			BlockWatcherOptions{},
+		)
+
+		status := blockWatcher.computeValidatorStatus(block, nil, extendedCommit)
+		assert.Equal(t, 2, len(status))
+		assert.Equal(t, true, status[0].VoteExtension)
+		assert.Equal(t, false, status[1].VoteExtension)
+
+		blockInfo := NewBlockInfo(block, status)
+		blockInfo.VoteExtensions = true
+		blockWatcher.handleBlockInfo(context.Background(), blockInfo)
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(blockWatcher.metrics.IncludedVoteExtensions.WithLabelValues("", kilnAddress, "Kiln")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.MissedVoteExtensions.WithLabelValues("", kilnAddress, "Kiln")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(blockWatcher.metrics.IncludedVoteExtensions.WithLabelValues("", otherAddress, "Other")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(blockWatcher.metrics.MissedVoteExtensions.WithLabelValues("", otherAddress, "Other")))
+	})
+}
+
+func TestExpectedProposer(t *testing.T) {
+	newValidator := func(seed byte, power int64, priority int64) *types.Validator {
+		val := types.NewValidator(ed25519.GenPrivKeyFromSecret([]byte{seed}).PubKey(), power)
+		val.ProposerPriority = priority
+		return val
+	}
+
+	validators := []*types.Validator{
+		newValidator(1, 10, -5),
+		newValidator(2, 20, 0),
+		newValidator(3, 30, 5),
+	}
+
+	// Proposer at round 0 has the highest priority of the set at its height
+	assert.Equal(t, validators[2].Address.String(), expectedProposer(validators))
+	assert.Equal(t, "", expectedProposer(nil))
+
+	// Validator set is not modified
+	assert.Equal(t, int64(5), validators[2].ProposerPriority)
+
+	t.Run("Validator Set Change", func(t *testing.T) {
+		// A new validator with the highest priority joins the set at height 100,
+		// while the priorities of height 99 incremented would elect validator 3.
+		sets := map[int64][]*types.Validator{
+			99: {
+				newValidator(3, 30, 5),
+				newValidator(2, 20, 0),
+				newValidator(1, 10, -5),
+			},
+			100: {
+				newValidator(4, 40, 40),
+				newValidator(3, 30, 0),
+				newValidator(2, 20, -15),
+				newValidator(1, 10, -25),
+			},
+		}
+		node, heights := newValidatorsNode(t, 100, func(height int64) []*types.Validator {
+			return sets[height]
+		})
+
+		blockWatcher := NewBlockWatcher(
+			[]TrackedValidator{},
+			metrics.New("cosmos_validator_watcher"),
+			&bytes.Buffer{},
+			nil,
+			[]BlockWebhook{},
+			BlockWatcherOptions{},
+		)
+		ctx := context.Background()
+
+		validatorSet, err := blockWatcher.validatorSetWithPriorities(ctx, node, 100)
+		assert.NilError(t, err)
+		assert.Equal(t, sets[100][0].Address.String(), expectedProposer(validatorSet.Validators))
+
+		// Sets fetched at a given height are reused
+		_, err = blockWatcher.validatorSetWithPriorities(ctx, node, 100)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []int64{100}, *heights)
+	})
+
+	t.Run("Same Validators At Another Height", func(t *testing.T) {
+		// Same validators with different priorities at heights 99 and 100
+		sets := map[int64][]*types.Validator{
+			99: {
+				newValidator(2, 20, 10),
+				newValidator(1, 10, -10),
+			},
+			100: {
+				newValidator(2, 20, -5),
+				newValidator(1, 10, 5),
+			},
+		}
+		node, heights := newValidatorsNode(t, 100, func(height int64) []*types.Validator {
+			return sets[height]
+		})
+
+		blockWatcher := NewBlockWatcher(
+			[]TrackedValidator{},
+			metrics.New("cosmos_validator_watcher"),
+			&bytes.Buffer{},
+			nil,
+			[]BlockWebhook{},
+			BlockWatcherOptions{},
+		)
+		ctx := context.Background()
+
+		// Set of height 99 is reused for height 100 by validators hash...
+		hash := types.NewValidatorSet(sets[99]).Hash()
+		blockWatcher.validatorSets.AddHeader(&types.Header{Height: 99, ValidatorsHash: hash, NextValidatorsHash: hash})
+		_, err := blockWatcher.validatorSetAt(ctx, node, 99)
+		assert.NilError(t, err)
+		validatorSet, err := blockWatcher.validatorSetAt(ctx, node, 100)
+		assert.NilError(t, err)
+		assert.Equal(t, sets[99][0].Address.String(), expectedProposer(validatorSet.Validators))
+
+		// ...but not to find out the proposer
+		validatorSet, err = blockWatcher.validatorSetWithPriorities(ctx, node, 100)
+		assert.NilError(t, err)
+		assert.Equal(t, sets[100][1].Address.String(), expectedProposer(validatorSet.Validators))
+		assert.DeepEqual(t, []int64{99, 100}, *heights)
+	})
+}
+
+func TestProposalWindow(t *testing.T) {
+	window := newProposalWindow(3)
+
+	window.Add(0.5, true)
+	window.Add(0.5, false)
+	window.Add(0.5, true)
+	assert.Equal(t, 1.5, window.Expected())
+	assert.Equal(t, 2, window.Proposed())
+
+	// Oldest entry is evicted
+	window.Add(0.25, false)
+	assert.Equal(t, 1.25, window.Expected())
+	assert.Equal(t, 1, window.Proposed())
+}
+
+func TestDivergenceTracker(t *testing.T) {
+	tracker := newDivergenceTracker(10)
+
+	hashes := blockHashes{Node: "node-1", BlockHash: "A", AppHash: "B", LastResultsHash: "C"}
+	assert.Assert(t, tracker.Add(100, hashes) == nil)
+
+	// Same hashes from another node
+	hashes.Node = "node-2"
+	assert.Assert(t, tracker.Add(100, hashes) == nil)
+
+	// Different last results hash
+	diverging := blockHashes{Node: "node-3", BlockHash: "A", AppHash: "B", LastResultsHash: "D"}
+	expected := tracker.Add(100, diverging)
+	assert.Assert(t, expected != nil)
+	assert.Equal(t, "node-1", expected.Node)
+	assert.DeepEqual(t, []string{"last_results_hash"}, diverging.Diff(*expected))
+
+	// Each node is only compared once per height
+	assert.Assert(t, tracker.Add(100, diverging) == nil)
+
+	// Old heights are forgotten
+	assert.Assert(t, tracker.Add(110, hashes) == nil)
+	assert.Equal(t, 1, len(tracker.heights))
+	assert.Assert(t, tracker.Add(100, diverging) == nil)
+}
+
+func TestValidatorSetCache(t *testing.T) {
+	newValidator := func(seed byte, power int64) *types.Validator {
+		return types.NewValidator(ed25519.GenPrivKeyFromSecret([]byte{seed}).PubKey(), power)
+	}
+
+	// Sets are ordered by voting power as returned by the nodes
+	setA := []*types.Validator{newValidator(2, 20), newValidator(1, 10)}
+	setB := []*types.Validator{newValidator(3, 30), newValidator(1, 10)}
+	hashA := types.NewValidatorSet(setA).Hash()
+	hashB := types.NewValidatorSet(setB).Hash()
+
+	cache := newValidatorSetCache(10)
+	cache.Add(99, newIndexedValidatorSet(setA))
+
+	// Set fetched at a given height
+	assert.Equal(t, 2, cache.Get(99).Size())
+	assert.Assert(t, cache.Get(100) == nil)
+
+	// Same validators hash announced by the headers
+	cache.AddHeader(&types.Header{Height: 99, ValidatorsHash: hashA, NextValidatorsHash: hashA})
+	cache.AddHeader(&types.Header{Height: 100, ValidatorsHash: hashA, NextValidatorsHash: hashB})
+	assert.Equal(t, setA[0].Address.String(), cache.Get(100).Validators[0].Address.String())
+	assert.Equal(t, int64(10), cache.Get(100).Get(setA[1].Address.String()).VotingPower)
+	assert.Assert(t, cache.Get(100).Get(setB[0].Address.String()) == nil)
+
+	// Validator set changes at the next height
+	assert.Assert(t, cache.Get(101) == nil)
+	cache.Add(101, newIndexedValidatorSet(setB))
+	assert.Equal(t, setB[0].Address.String(), cache.Get(101).Validators[0].Address.String())
+
+	// Old heights are forgotten
+	cache.AddHeader(&types.Header{Height: 110, ValidatorsHash: hashB, NextValidatorsHash: hashB})
+	assert.Assert(t, cache.Get(99) == nil)
+	assert.Assert(t, cache.Get(100) == nil)
+	assert.Equal(t, 1, len(cache.byHash))
+}
+
+func BenchmarkComputeValidatorStatus(b *testing.B) {
+	const (
+		setSize = 1000
+		tracked = 100
+	)
+
+	validatorSet := make([]*types.Validator, 0, setSize)
+	signatures := make([]types.CommitSig, 0, setSize)
+	for i := 0; i < setSize; i++ {
+		val := types.NewValidator(ed25519.GenPrivKeyFromSecret([]byte{byte(i), byte(i >> 8)}).PubKey(), 10)
+		validatorSet = append(validatorSet, val)
+		signatures = append(signatures, types.CommitSig{BlockIDFlag: types.BlockIDFlagCommit, ValidatorAddress: val.Address})
+	}
+
+	// Track the validators at the end of the set
+	trackedValidators := make([]TrackedValidator, 0, tracked)
+	for _, val := range validatorSet[setSize-tracked:] {
+		trackedValidators = append(trackedValidators, TrackedValidator{Address: val.Address.String()})
+	}
+
+	blockWatcher := NewBlockWatcher(
+		trackedValidators,
+		metrics.New("cosmos_validator_watcher"),
+		&bytes.Buffer{},
+		nil,
+		[]BlockWebhook{},
+		BlockWatcherOptions{},
+	)
+	block := &types.Block{
+		Header:     types.Header{Height: 100},
+		LastCommit: &types.Commit{Height: 99, Signatures: signatures},
+	}
+	indexedSet := newIndexedValidatorSet(validatorSet)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		blockWatcher.computeValidatorStatus(block, indexedSet, nil)
+	}
+}
+
+// newValidatorsNode returns a node whose validator sets are served by a test
+// RPC server, along with the heights requested to the server.
+func newValidatorsNode(t *testing.T, latestHeight int64, validatorSet func(height int64) []*types.Validator) (*rpc.Node, *[]int64) {
+	heights := []int64{}
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		var req rpctypes.RPCRequest
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var params struct {
+			Height  *int64 `json:"height"`
+			Page    *int   `json:"page"`
+			PerPage *int   `json:"per_page"`
+		}
+		assert.NilError(t, cmtjson.Unmarshal(req.Params, &params))
+
+		height := latestHeight
+		if params.Height != nil {
+			height = *params.Height
+		}
+		heights = append(heights, height)
+
+		validators := validatorSet(height)
+		start := min((*params.Page-1)**params.PerPage, len(validators))
+		end := min(start+*params.PerPage, len(validators))
+		resp := rpctypes.NewRPCSuccessResponse(req.ID, &ctypes.ResultValidators{
+			BlockHeight: height,
+			Validators:  validators[start:end],
+			Count:       end - start,
+			Total:       len(validators),
+		})
+		assert.NilError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := http.New(server.URL, "/websocket")
+	assert.NilError(t, err)
+
+	return rpc.NewNode(client), &heights
+}
+
+func TestFetchValidatorSet(t *testing.T) {
+	const (
+		setSize      = 250
+		latestHeight = 1000
+	)
+
+	validators := make([]*types.Validator, 0, setSize)
+	for i := 0; i < setSize; i++ {
+		validators = append(validators, types.NewValidator(ed25519.GenPrivKeyFromSecret([]byte{byte(i)}).PubKey(), 10))
+	}
+
+	node, heights := newValidatorsNode(t, latestHeight, func(int64) []*types.Validator {
+		return validators
+	})
+
+	blockWatcher := NewBlockWatcher(
+		[]TrackedValidator{},
+		metrics.New("cosmos_validator_watcher"),
+		&bytes.Buffer{},
+		nil,
+		[]BlockWebhook{},
+		BlockWatcherOptions{},
+	)
+
+	fetched, height, err := blockWatcher.fetchValidatorSet(context.Background(), node, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, setSize, len(fetched))
+	assert.Equal(t, int64(latestHeight), height)
+	assert.Equal(t, validators[setSize-1].Address.String(), fetched[setSize-1].Address.String())
+
+	// All the pages are fetched at the height of the first one
+	assert.DeepEqual(t, []int64{latestHeight, latestHeight, latestHeight}, *heights)
 }
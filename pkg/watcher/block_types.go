@@ -1,18 +1,50 @@
 package watcher
 
 import (
+	"sync"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
 	"github.com/cometbft/cometbft/types"
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/rpc"
 	"github.com/shopspring/decimal"
 )
 
+// mempoolSample is the number of pending transactions of a node at a time.
+type mempoolSample struct {
+	Total int
+	Time  time.Time
+}
+
+// poolBlock is a block delivered by a node of the pool.
+type poolBlock struct {
+	node  *rpc.Node
+	block *types.Block
+}
+
 type BlockInfo struct {
 	ChainID          string
 	Height           int64
-	Transactions     int
+	Time             time.Time
+	Transactions     int // -1 if unknown
 	TotalValidators  int
 	SignedValidators int
 	ProposerAddress  string
-	ValidatorStatus  []ValidatorStatus
+	CommitRound      int32 // round at which the previous block has been committed
+	TotalVotingPower int64
+	VoteExtensions   bool // vote extensions of the last commit are available (unknown, thus not counted, otherwise)
+
+	// Validator expected to propose the previous block at round 0
+	// (only set when the previous block has been committed at round > 0)
+	ExpectedProposerAddress string
+
+	// Number of pending transactions in the mempool sampled by the node
+	// watcher while the block was proposed, ie. since the previous block was
+	// received (-1 if unknown)
+	MempoolTransactions int
+
+	ValidatorStatus []ValidatorStatus
 }
 
 func NewBlockInfo(block *types.Block, validatorStatus []ValidatorStatus) *BlockInfo {
@@ -27,11 +59,13 @@ func NewBlockInfo(block *types.Block, validatorStatus []ValidatorStatus) *BlockI
 	return &BlockInfo{
 		ChainID:          block.Header.ChainID,
 		Height:           block.Header.Height,
+		Time:             block.Header.Time,
 		Transactions:     block.Txs.Len(),
 		TotalValidators:  len(block.LastCommit.Signatures),
 		SignedValidators: signedValidators,
 		ValidatorStatus:  validatorStatus,
 		ProposerAddress:  block.Header.ProposerAddress.String(),
+		CommitRound:      block.LastCommit.Round,
 	}
 }
 
@@ -44,10 +78,339 @@ func (b *BlockInfo) SignedRatio() decimal.Decimal {
 		Div(decimal.NewFromInt(int64(b.TotalValidators)))
 }
 
+// extractExtendedCommitInfo returns the extended commit info of the last
+// commit, as injected by the proposer in the first transaction of the block on
+// chains with vote extensions enabled (ABCI++). It returns nil when vote
+// extensions are disabled or when the first transaction is not an extended
+// commit info matching the last commit (the vote extensions of the block are
+// then unknown rather than missed).
+func extractExtendedCommitInfo(block *types.Block, voteExtensionsHeight int64) *abci.ExtendedCommitInfo {
+	// Vote extensions for height H-1 are included in block H
+	if voteExtensionsHeight == 0 || block.Height-1 < voteExtensionsHeight {
+		return nil
+	}
+	if len(block.Txs) == 0 || block.LastCommit == nil {
+		return nil
+	}
+
+	info := &abci.ExtendedCommitInfo{}
+	if err := info.Unmarshal(block.Txs[0]); err != nil {
+		return nil
+	}
+
+	// Ensure the decoded transaction is really an extended commit info
+	if info.Round != block.LastCommit.Round || len(info.Votes) != len(block.LastCommit.Signatures) {
+		return nil
+	}
+
+	return info
+}
+
 type ValidatorStatus struct {
-	Address string
-	Label   string
-	Bonded  bool
-	Signed  bool
-	Rank    int
+	Address       string
+	Label         string
+	Bonded        bool
+	Signed        bool
+	Rank          int
+	VotingPower   int64
+	Flag          types.BlockIDFlag
+	Timestamp     time.Time // precommit signature timestamp
+	VoteExtension bool      // vote extension included in the extended commit
+}
+
+// SignatureFlag returns a label describing how the validator signed the block:
+// commit when it signed the block, nil when it voted nil and absent otherwise.
+func (s ValidatorStatus) SignatureFlag() string {
+	switch {
+	case s.Signed:
+		return "commit"
+	case s.Flag == types.BlockIDFlagNil:
+		return "nil"
+	default:
+		return "absent"
+	}
+}
+
+// expectedProposer returns the address of the validator expected to propose at
+// round 0 given the validator set of the same height, ie. the validator with
+// the highest proposer priority.
+func expectedProposer(validators []*types.Validator) string {
+	if len(validators) == 0 {
+		return ""
+	}
+
+	validatorSet := &types.ValidatorSet{Validators: validators}
+
+	return validatorSet.GetProposer().Address.String()
+}
+
+// proposalWindow keeps track of expected & actual proposed blocks of a
+// validator over a sliding window of blocks.
+type proposalWindow struct {
+	expected    []float64
+	proposed    []bool
+	index       int
+	sumExpected float64
+	sumProposed int
+}
+
+func newProposalWindow(size int) *proposalWindow {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &proposalWindow{
+		expected: make([]float64, 0, size),
+		proposed: make([]bool, 0, size),
+	}
+}
+
+// Add records a new block with the probability for the validator to propose
+// it and whether it actually proposed it.
+func (p *proposalWindow) Add(expected float64, proposed bool) {
+	if len(p.expected) < cap(p.expected) {
+		p.expected = append(p.expected, expected)
+		p.proposed = append(p.proposed, proposed)
+	} else {
+		p.sumExpected -= p.expected[p.index]
+		if p.proposed[p.index] {
+			p.sumProposed--
+		}
+		p.expected[p.index] = expected
+		p.proposed[p.index] = proposed
+		p.index = (p.index + 1) % cap(p.expected)
+	}
+
+	p.sumExpected += expected
+	if proposed {
+		p.sumProposed++
+	}
+}
+
+// Expected returns the expected number of proposed blocks over the window.
+func (p *proposalWindow) Expected() float64 {
+	return p.sumExpected
+}
+
+// Proposed returns the actual number of proposed blocks over the window.
+func (p *proposalWindow) Proposed() int {
+	return p.sumProposed
+}
+
+// blockHashes are the hashes of a block as reported by a node.
+type blockHashes struct {
+	Node            string
+	BlockHash       string
+	AppHash         string
+	LastResultsHash string
+}
+
+func newBlockHashes(node string, block *types.Block) blockHashes {
+	return blockHashes{
+		Node:            node,
+		BlockHash:       block.Hash().String(),
+		AppHash:         block.AppHash.String(),
+		LastResultsHash: block.LastResultsHash.String(),
+	}
+}
+
+// Diff returns the names of the hashes differing from the given ones.
+func (h blockHashes) Diff(other blockHashes) []string {
+	fields := []string{}
+	if h.BlockHash != other.BlockHash {
+		fields = append(fields, "block_hash")
+	}
+	if h.AppHash != other.AppHash {
+		fields = append(fields, "app_hash")
+	}
+	if h.LastResultsHash != other.LastResultsHash {
+		fields = append(fields, "last_results_hash")
+	}
+	return fields
+}
+
+// divergenceTracker keeps the hashes reported by the nodes for the latest
+// heights to detect nodes disagreeing on the state of the chain.
+type divergenceTracker struct {
+	mu        sync.Mutex
+	size      int64
+	maxHeight int64
+	heights   map[int64][]blockHashes
+}
+
+func newDivergenceTracker(size int64) *divergenceTracker {
+	return &divergenceTracker{
+		size:    size,
+		heights: make(map[int64][]blockHashes),
+	}
+}
+
+// Add records the hashes reported by a node for the given height. It returns
+// the hashes first reported for this height by another node when they differ
+// (nil otherwise). A node is only compared once per height.
+func (t *divergenceTracker) Add(height int64, hashes blockHashes) *blockHashes {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Forget about old heights
+	if height > t.maxHeight {
+		t.maxHeight = height
+		for h := range t.heights {
+			if h <= t.maxHeight-t.size {
+				delete(t.heights, h)
+			}
+		}
+	}
+	if height <= t.maxHeight-t.size {
+		return nil
+	}
+
+	reported := t.heights[height]
+	for _, other := range reported {
+		if other.Node == hashes.Node {
+			return nil
+		}
+	}
+	t.heights[height] = append(reported, hashes)
+
+	if len(reported) > 0 && len(hashes.Diff(reported[0])) > 0 {
+		return &reported[0]
+	}
+	return nil
+}
+
+// indexedValidatorSet is a CometBFT validator set indexed by address, built
+// once each time a set is fetched.
+type indexedValidatorSet struct {
+	Validators []*types.Validator
+	hash       string // validators hash (ignores proposer priorities)
+	byAddress  map[string]*types.Validator
+}
+
+func newIndexedValidatorSet(validators []*types.Validator) *indexedValidatorSet {
+	set := &indexedValidatorSet{
+		Validators: validators,
+		hash:       bytes.HexBytes((&types.ValidatorSet{Validators: validators}).Hash()).String(),
+		byAddress:  make(map[string]*types.Validator, len(validators)),
+	}
+	for _, val := range validators {
+		set.byAddress[val.Address.String()] = val
+	}
+	return set
+}
+
+// Get returns the validator with the given address (nil if not in the set).
+func (s *indexedValidatorSet) Get(address string) *types.Validator {
+	if s == nil {
+		return nil
+	}
+	return s.byAddress[address]
+}
+
+// Size returns the number of validators in the set.
+func (s *indexedValidatorSet) Size() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.Validators)
+}
+
+// validatorSetCache keeps the validator sets of the latest heights. Sets are
+// also indexed by hash so that a set fetched once is reused for all the
+// heights sharing the same validators hash (as announced by the headers).
+type validatorSetCache struct {
+	mu        sync.Mutex
+	size      int64
+	maxHeight int64
+	hashes    map[int64]string                // validators hash per height
+	fetched   map[int64]*indexedValidatorSet  // sets fetched per height
+	byHash    map[string]*indexedValidatorSet // fetched sets per hash
+}
+
+func newValidatorSetCache(size int64) *validatorSetCache {
+	return &validatorSetCache{
+		size:    size,
+		hashes:  make(map[int64]string),
+		fetched: make(map[int64]*indexedValidatorSet),
+		byHash:  make(map[string]*indexedValidatorSet),
+	}
+}
+
+// AddHeader records the validators hashes announced by a block header for its
+// height and the next one.
+func (c *validatorSetCache) AddHeader(header *types.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hashes[header.Height] = header.ValidatorsHash.String()
+	c.hashes[header.Height+1] = header.NextValidatorsHash.String()
+	c.prune(header.Height + 1)
+}
+
+// Add records the validator set fetched at the given height.
+func (c *validatorSetCache) Add(height int64, set *indexedValidatorSet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.byHash[set.hash] = set
+	c.fetched[height] = set
+	c.prune(height)
+}
+
+// Get returns the validator set at the given height (nil if unknown). The
+// set may have been fetched at another height with the same validators, in
+// which case its proposer priorities don't match the given height.
+func (c *validatorSetCache) Get(height int64) *indexedValidatorSet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if set, ok := c.fetched[height]; ok {
+		return set
+	}
+	if hash, ok := c.hashes[height]; ok {
+		return c.byHash[hash]
+	}
+	return nil
+}
+
+// GetFetched returns the validator set fetched at the given height, along
+// with its proposer priorities at that height (nil if not fetched).
+func (c *validatorSetCache) GetFetched(height int64) *indexedValidatorSet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.fetched[height]
+}
+
+// prune forgets about the heights out of the cache window and the sets no
+// longer referenced by any height.
+func (c *validatorSetCache) prune(height int64) {
+	if height <= c.maxHeight {
+		return
+	}
+	c.maxHeight = height
+
+	for h := range c.hashes {
+		if h <= c.maxHeight-c.size {
+			delete(c.hashes, h)
+		}
+	}
+	for h := range c.fetched {
+		if h <= c.maxHeight-c.size {
+			delete(c.fetched, h)
+		}
+	}
+
+	referenced := make(map[string]bool, len(c.hashes))
+	for _, hash := range c.hashes {
+		referenced[hash] = true
+	}
+	for _, set := range c.fetched {
+		referenced[set.hash] = true
+	}
+	for hash := range c.byHash {
+		if !referenced[hash] {
+			delete(c.byHash, hash)
+		}
+	}
 }
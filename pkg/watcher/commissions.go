@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/types"
 	distribution "github.com/cosmos/cosmos-sdk/x/distribution/types"
 	"github.com/kilnfi/cosmos-validator-watcher/pkg/metrics"
@@ -17,47 +16,45 @@ type CommissionWatcher struct {
 	validators []TrackedValidator
 	metrics    *metrics.Metrics
 	pool       *rpc.Pool
+	options    CommissionWatcherOptions
 }
 
-func NewCommissionsWatcher(validators []TrackedValidator, metrics *metrics.Metrics, pool *rpc.Pool) *CommissionWatcher {
+type CommissionWatcherOptions struct {
+	// When to fetch the commissions (default: every minute)
+	Schedule rpc.Schedule
+}
+
+func NewCommissionsWatcher(validators []TrackedValidator, metrics *metrics.Metrics, pool *rpc.Pool, options CommissionWatcherOptions) *CommissionWatcher {
 	return &CommissionWatcher{
 		validators: validators,
 		metrics:    metrics,
 		pool:       pool,
+		options:    options,
 	}
 }
 
 func (w *CommissionWatcher) Start(ctx context.Context) error {
-	ticker := time.NewTicker(1 * time.Minute)
-
-	for {
-		node := w.pool.GetSyncedNode()
-		if node == nil {
-			log.Warn().Msg("no node available to fetch validators commissions")
-		} else if err := w.fetchCommissions(ctx, node); err != nil {
+	schedule := w.options.Schedule.WithDefaultInterval(time.Minute)
+	schedule.Run(ctx, w.pool.BestHeight, func() {
+		if err := w.fetchCommissions(ctx); err != nil {
 			log.Error().Err(err).Msg("failed to fetch validators commissions")
 		}
+	})
 
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-ticker.C:
-		}
-	}
+	return nil
 }
 
-func (w *CommissionWatcher) fetchCommissions(ctx context.Context, node *rpc.Node) error {
+func (w *CommissionWatcher) fetchCommissions(ctx context.Context) error {
 	for _, validator := range w.validators {
-		if err := w.fetchValidatorCommission(ctx, node, validator); err != nil {
+		if err := w.fetchValidatorCommission(ctx, validator); err != nil {
 			log.Error().Err(err).Msgf("failed to fetch commission for validator %s", validator.OperatorAddress)
 		}
 	}
 	return nil
 }
 
-func (w *CommissionWatcher) fetchValidatorCommission(ctx context.Context, node *rpc.Node, validator TrackedValidator) error {
-	clientCtx := (client.Context{}).WithClient(node.Client)
-	queryClient := distribution.NewQueryClient(clientCtx)
+func (w *CommissionWatcher) fetchValidatorCommission(ctx context.Context, validator TrackedValidator) error {
+	queryClient := distribution.NewQueryClient(w.pool.ConnFor("commissions"))
 
 	commissionResq, err := queryClient.ValidatorCommission(ctx, &distribution.QueryValidatorCommissionRequest{
 		ValidatorAddress: validator.OperatorAddress,
@@ -66,7 +63,7 @@ func (w *CommissionWatcher) fetchValidatorCommission(ctx context.Context, node *
 		return fmt.Errorf("failed to get validators: %w", err)
 	}
 
-	w.handleValidatorCommission(node.ChainID(), validator, commissionResq.Commission.Commission)
+	w.handleValidatorCommission(w.pool.ChainID, validator, commissionResq.Commission.Commission)
 
 	return nil
 }
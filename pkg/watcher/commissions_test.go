@@ -23,6 +23,7 @@ func TestCommissionsWatcher(t *testing.T) {
 		[]TrackedValidator{kilnValidator},
 		metrics.New("cosmos_validator_watcher"),
 		nil,
+		CommissionWatcherOptions{},
 	)
 
 	t.Run("Handle Commissions", func(t *testing.T) {
@@ -0,0 +1,189 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/metrics"
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+type OnNodeMempool func(ctx context.Context, n *rpc.Node, mempool *ctypes.ResultUnconfirmedTxs)
+
+type NodeWatcher struct {
+	metrics   *metrics.Metrics
+	pool      *rpc.Pool
+	options   NodeWatcherOptions
+	onMempool []OnNodeMempool
+
+	mu        sync.Mutex
+	connected map[string]bool
+}
+
+type NodeWatcherOptions struct {
+	// When to export the node info: peers, mempool, versions... (default: every 30s)
+	InfoSchedule rpc.Schedule
+
+	// When to compare the height of the nodes (default: every 5s)
+	HealthSchedule rpc.Schedule
+}
+
+func NewNodeWatcher(metrics *metrics.Metrics, pool *rpc.Pool, options NodeWatcherOptions) *NodeWatcher {
+	return &NodeWatcher{
+		metrics:   metrics,
+		pool:      pool,
+		options:   options,
+		connected: make(map[string]bool),
+	}
+}
+
+// OnMempool registers a callback called each time the mempool of a node is
+// sampled (must be registered before Start).
+func (w *NodeWatcher) OnMempool(callback OnNodeMempool) {
+	w.onMempool = append(w.onMempool, callback)
+}
+
+func (w *NodeWatcher) Start(ctx context.Context) error {
+	// Only the interval & jitter of the schedules are used
+	infoSchedule := w.options.InfoSchedule.WithDefaultInterval(30 * time.Second)
+	healthSchedule := w.options.HealthSchedule.WithDefaultInterval(5 * time.Second)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		healthSchedule.Run(ctx, nil, w.syncNodesHealth)
+	}()
+
+	infoSchedule.Run(ctx, nil, func() {
+		w.syncNodesInfo(ctx)
+	})
+	wg.Wait()
+
+	return nil
+}
+
+func (w *NodeWatcher) syncNodesInfo(ctx context.Context) {
+	for _, node := range w.pool.Nodes {
+		w.syncNodeInfo(ctx, node)
+	}
+}
+
+// syncNodesHealth compares the height of each node with the best height of
+// the pool and reports whether the pool considers the node healthy.
+func (w *NodeWatcher) syncNodesHealth() {
+	bestHeight := w.pool.BestHeight()
+
+	for _, node := range w.pool.Nodes {
+		lag := int64(0)
+		if node.Height() > 0 {
+			lag = bestHeight - node.Height()
+		}
+		w.metrics.NodeHeightLag.WithLabelValues(w.pool.ChainID, node.Endpoint()).Set(float64(lag))
+		w.metrics.NodeHealthy.WithLabelValues(w.pool.ChainID, node.Endpoint()).Set(metrics.BoolToFloat64(w.pool.IsHealthy(node)))
+	}
+}
+
+// syncNodeInfo queries each endpoint separately so that the metrics of the
+// endpoints answering are still exported when others are blocked (eg. net_info
+// on public nodes). The status is queried directly: the node keeps its own
+// status up to date and must not be changed from here.
+func (w *NodeWatcher) syncNodeInfo(ctx context.Context, n *rpc.Node) {
+	status, err := n.Client.Status(ctx)
+	if err != nil {
+		log.Warn().Err(err).Str("node", n.Redacted()).Msg("failed to get status")
+	}
+
+	var abciInfo *abcitypes.ResponseInfo
+	if resp, err := n.Client.ABCIInfo(ctx); err != nil {
+		log.Warn().Err(err).Str("node", n.Redacted()).Msg("failed to get abci info")
+	} else {
+		abciInfo = &resp.Response
+	}
+
+	netInfo, err := n.Client.NetInfo(ctx)
+	if err != nil {
+		log.Warn().Err(err).Str("node", n.Redacted()).Msg("failed to get net info")
+	}
+
+	mempool, err := n.Client.NumUnconfirmedTxs(ctx)
+	if err != nil {
+		log.Warn().Err(err).Str("node", n.Redacted()).Msg("failed to get mempool")
+	} else {
+		for _, onMempool := range w.onMempool {
+			onMempool(ctx, n, mempool)
+		}
+	}
+
+	w.handleNodeInfo(n, status, abciInfo, netInfo, mempool)
+}
+
+// handleNodeInfo exports the node metrics, skipping the ones depending on an
+// endpoint which failed to answer (nil).
+func (w *NodeWatcher) handleNodeInfo(n *rpc.Node, status *ctypes.ResultStatus, abciInfo *abcitypes.ResponseInfo, netInfo *ctypes.ResultNetInfo, mempool *ctypes.ResultUnconfirmedTxs) {
+	chainID := w.pool.ChainID
+	endpoint := n.Endpoint()
+
+	if status != nil && abciInfo != nil {
+		// Versions may change after an upgrade: drop the previous series
+		w.metrics.NodeInfo.DeletePartialMatch(prometheus.Labels{"node": endpoint})
+		w.metrics.NodeInfo.WithLabelValues(chainID, endpoint, status.NodeInfo.Version, abciInfo.Version, status.NodeInfo.Moniker).Set(1)
+	}
+
+	if status != nil {
+		w.metrics.NodeVotingPower.WithLabelValues(chainID, endpoint).Set(float64(status.ValidatorInfo.VotingPower))
+	}
+
+	if netInfo != nil {
+		inbound, outbound := 0, 0
+		for _, peer := range netInfo.Peers {
+			if peer.IsOutbound {
+				outbound++
+			} else {
+				inbound++
+			}
+		}
+		w.metrics.NodePeers.WithLabelValues(chainID, endpoint, "inbound").Set(float64(inbound))
+		w.metrics.NodePeers.WithLabelValues(chainID, endpoint, "outbound").Set(float64(outbound))
+	}
+
+	if mempool != nil {
+		w.metrics.NodeMempoolTxs.WithLabelValues(chainID, endpoint).Set(float64(mempool.Total))
+		w.metrics.NodeMempoolSize.WithLabelValues(chainID, endpoint).Set(float64(mempool.TotalBytes))
+	}
+}
+
+func (w *NodeWatcher) OnNodeCall(ctx context.Context, n *rpc.Node, method string, duration time.Duration, err error) {
+	w.metrics.NodeRPCLatency.WithLabelValues(w.pool.ChainID, n.Endpoint(), method).Observe(duration.Seconds())
+
+	errors := w.metrics.NodeRPCErrors.WithLabelValues(w.pool.ChainID, n.Endpoint(), method)
+	if err != nil {
+		errors.Inc()
+	}
+}
+
+func (w *NodeWatcher) OnNodeThrottle(ctx context.Context, n *rpc.Node, method string) {
+	w.metrics.NodeThrottled.WithLabelValues(w.pool.ChainID, n.Endpoint(), method).Inc()
+}
+
+func (w *NodeWatcher) OnNodeStreaming(ctx context.Context, n *rpc.Node, streaming bool) {
+	w.metrics.NodeStreaming.WithLabelValues(w.pool.ChainID, n.Endpoint()).Set(metrics.BoolToFloat64(streaming))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !streaming {
+		return
+	}
+
+	// Only count the connections following the first one
+	if w.connected[n.Endpoint()] {
+		w.metrics.NodeReconnects.WithLabelValues(w.pool.ChainID, n.Endpoint()).Inc()
+	}
+	w.connected[n.Endpoint()] = true
+}
@@ -0,0 +1,149 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/p2p"
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/metrics"
+	"github.com/kilnfi/cosmos-validator-watcher/pkg/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/assert"
+)
+
+// newSyncedNode returns a node whose status is served by a test RPC server.
+func newSyncedNode(t *testing.T, moniker string, height int64) *rpc.Node {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpctypes.RPCRequest
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		resp := rpctypes.NewRPCSuccessResponse(req.ID, &ctypes.ResultStatus{
+			NodeInfo: p2p.DefaultNodeInfo{Moniker: moniker},
+			SyncInfo: ctypes.SyncInfo{
+				LatestBlockHeight: height,
+				LatestBlockTime:   time.Now(),
+			},
+		})
+		assert.NilError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := rpchttp.New(server.URL, "/websocket")
+	assert.NilError(t, err)
+
+	node := rpc.NewNode(client)
+	_, err = node.Status(context.Background())
+	assert.NilError(t, err)
+
+	return node
+}
+
+func TestNodeWatcher(t *testing.T) {
+	client, err := rpchttp.New("http://localhost:26657", "/websocket")
+	assert.NilError(t, err)
+
+	node := rpc.NewNode(client)
+	watcher := NewNodeWatcher(metrics.New("cosmos_validator_watcher"), rpc.NewPool("chain-42", []*rpc.Node{node}), NodeWatcherOptions{})
+
+	t.Run("Handle Node Calls", func(t *testing.T) {
+		ctx := context.Background()
+
+		watcher.OnNodeCall(ctx, node, "status", 100*time.Millisecond, nil)
+		watcher.OnNodeCall(ctx, node, "status", 200*time.Millisecond, errors.New("connection refused"))
+		watcher.OnNodeCall(ctx, node, "block", 50*time.Millisecond, nil)
+
+		assert.Equal(t, 2, testutil.CollectAndCount(watcher.metrics.NodeRPCLatency))
+		assert.Equal(t, float64(1), testutil.ToFloat64(watcher.metrics.NodeRPCErrors.WithLabelValues("chain-42", node.Endpoint(), "status")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(watcher.metrics.NodeRPCErrors.WithLabelValues("chain-42", node.Endpoint(), "block")))
+	})
+
+	t.Run("Handle Node Streaming", func(t *testing.T) {
+		ctx := context.Background()
+
+		watcher.OnNodeStreaming(ctx, node, true)
+		assert.Equal(t, float64(1), testutil.ToFloat64(watcher.metrics.NodeStreaming.WithLabelValues("chain-42", node.Endpoint())))
+		assert.Equal(t, float64(0), testutil.ToFloat64(watcher.metrics.NodeReconnects.WithLabelValues("chain-42", node.Endpoint())))
+
+		watcher.OnNodeStreaming(ctx, node, false)
+		assert.Equal(t, float64(0), testutil.ToFloat64(watcher.metrics.NodeStreaming.WithLabelValues("chain-42", node.Endpoint())))
+
+		watcher.OnNodeStreaming(ctx, node, true)
+		assert.Equal(t, float64(1), testutil.ToFloat64(watcher.metrics.NodeStreaming.WithLabelValues("chain-42", node.Endpoint())))
+		assert.Equal(t, float64(1), testutil.ToFloat64(watcher.metrics.NodeReconnects.WithLabelValues("chain-42", node.Endpoint())))
+	})
+
+	t.Run("Handle Node Throttle", func(t *testing.T) {
+		watcher.OnNodeThrottle(context.Background(), node, "validators")
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(watcher.metrics.NodeThrottled.WithLabelValues("chain-42", node.Endpoint(), "validators")))
+	})
+
+	t.Run("Sync Nodes Health", func(t *testing.T) {
+		nodeA := newSyncedNode(t, "node-a", 100)
+		nodeB := newSyncedNode(t, "node-b", 90)
+		watcher := NewNodeWatcher(metrics.New("cosmos_validator_watcher"), rpc.NewPool("chain-42", []*rpc.Node{nodeA, nodeB}, rpc.MaxLag(5)), NodeWatcherOptions{})
+
+		watcher.syncNodesHealth()
+
+		assert.Equal(t, float64(0), testutil.ToFloat64(watcher.metrics.NodeHeightLag.WithLabelValues("chain-42", nodeA.Endpoint())))
+		assert.Equal(t, float64(10), testutil.ToFloat64(watcher.metrics.NodeHeightLag.WithLabelValues("chain-42", nodeB.Endpoint())))
+		assert.Equal(t, float64(1), testutil.ToFloat64(watcher.metrics.NodeHealthy.WithLabelValues("chain-42", nodeA.Endpoint())))
+		assert.Equal(t, float64(0), testutil.ToFloat64(watcher.metrics.NodeHealthy.WithLabelValues("chain-42", nodeB.Endpoint())))
+	})
+
+	t.Run("Handle Node Info", func(t *testing.T) {
+		status := &ctypes.ResultStatus{
+			NodeInfo: p2p.DefaultNodeInfo{
+				Network: "chain-42",
+				Version: "0.38.7",
+				Moniker: "sentry-1",
+			},
+			ValidatorInfo: ctypes.ValidatorInfo{
+				VotingPower: 42,
+			},
+		}
+		netInfo := &ctypes.ResultNetInfo{
+			Peers: []ctypes.Peer{
+				{IsOutbound: true},
+				{IsOutbound: false},
+				{IsOutbound: false},
+			},
+		}
+		mempool := &ctypes.ResultUnconfirmedTxs{Total: 12, TotalBytes: 3400}
+
+		watcher.handleNodeInfo(node, status, &abcitypes.ResponseInfo{Version: "v1.0.0"}, netInfo, mempool)
+		watcher.handleNodeInfo(node, status, &abcitypes.ResponseInfo{Version: "v2.0.0"}, netInfo, mempool)
+
+		assert.Equal(t, 1, testutil.CollectAndCount(watcher.metrics.NodeInfo))
+		assert.Equal(t, float64(1), testutil.ToFloat64(watcher.metrics.NodeInfo.WithLabelValues("chain-42", node.Endpoint(), "0.38.7", "v2.0.0", "sentry-1")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(watcher.metrics.NodePeers.WithLabelValues("chain-42", node.Endpoint(), "outbound")))
+		assert.Equal(t, float64(2), testutil.ToFloat64(watcher.metrics.NodePeers.WithLabelValues("chain-42", node.Endpoint(), "inbound")))
+		assert.Equal(t, float64(12), testutil.ToFloat64(watcher.metrics.NodeMempoolTxs.WithLabelValues("chain-42", node.Endpoint())))
+		assert.Equal(t, float64(3400), testutil.ToFloat64(watcher.metrics.NodeMempoolSize.WithLabelValues("chain-42", node.Endpoint())))
+		assert.Equal(t, float64(42), testutil.ToFloat64(watcher.metrics.NodeVotingPower.WithLabelValues("chain-42", node.Endpoint())))
+	})
+
+	t.Run("Handle Partial Node Info", func(t *testing.T) {
+		watcher := NewNodeWatcher(metrics.New("cosmos_validator_watcher"), rpc.NewPool("chain-42", []*rpc.Node{node}), NodeWatcherOptions{})
+		status := &ctypes.ResultStatus{
+			ValidatorInfo: ctypes.ValidatorInfo{VotingPower: 42},
+		}
+
+		// Net info & abci info failed to be fetched
+		watcher.handleNodeInfo(node, status, nil, nil, &ctypes.ResultUnconfirmedTxs{Total: 12})
+
+		assert.Equal(t, 0, testutil.CollectAndCount(watcher.metrics.NodeInfo))
+		assert.Equal(t, 0, testutil.CollectAndCount(watcher.metrics.NodePeers))
+		assert.Equal(t, float64(12), testutil.ToFloat64(watcher.metrics.NodeMempoolTxs.WithLabelValues("chain-42", node.Endpoint())))
+		assert.Equal(t, float64(42), testutil.ToFloat64(watcher.metrics.NodeVotingPower.WithLabelValues("chain-42", node.Endpoint())))
+	})
+}
@@ -47,7 +47,7 @@ func (w *StatusWatcher) OnNodeStatus(ctx context.Context, n *rpc.Node, status *c
 	chainID := ""
 
 	if status != nil {
-		synced = !status.SyncInfo.CatchingUp
+		synced = n.IsSynced()
 		blockHeight = status.SyncInfo.LatestBlockHeight
 		chainID = status.NodeInfo.Network
 	}
@@ -3,16 +3,15 @@ package watcher
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
-	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"cosmossdk.io/x/upgrade/types"
+	upgrade "cosmossdk.io/x/upgrade/types"
 	comettypes "github.com/cometbft/cometbft/types"
-	"github.com/cosmos/cosmos-sdk/client"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	gov "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
 	govbeta "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
-	"cosmossdk.io/x/upgrade/types"
-	upgrade "cosmossdk.io/x/upgrade/types"
 	"github.com/gogo/protobuf/codec"
 	"github.com/kilnfi/cosmos-validator-watcher/pkg/metrics"
 	"github.com/kilnfi/cosmos-validator-watcher/pkg/rpc"
@@ -26,6 +25,7 @@ type UpgradeWatcher struct {
 	webhook *webhook.Webhook
 	options UpgradeWatcherOptions
 
+	mu                sync.Mutex
 	nextUpgradePlan   *upgrade.Plan // known upgrade plan
 	latestBlockHeight int64         // latest block received
 	latestWebhookSent int64         // latest block for which webhook has been sent
@@ -34,6 +34,9 @@ type UpgradeWatcher struct {
 type UpgradeWatcherOptions struct {
 	CheckPendingProposals bool
 	GovModuleVersion      string
+
+	// When to fetch the upgrade plan (default: every minute)
+	Schedule rpc.Schedule
 }
 
 func NewUpgradeWatcher(metrics *metrics.Metrics, pool *rpc.Pool, webhook *webhook.Webhook, options UpgradeWatcherOptions) *UpgradeWatcher {
@@ -46,68 +49,53 @@ func NewUpgradeWatcher(metrics *metrics.Metrics, pool *rpc.Pool, webhook *webhoo
 }
 
 func (w *UpgradeWatcher) Start(ctx context.Context) error {
-	ticker := time.NewTicker(1 * time.Minute)
-
-	for {
-		node := w.pool.GetSyncedNode()
-		if node == nil {
-			log.Warn().Msg("no node available to fetch upgrade plan")
-		} else if err := w.fetchUpgrade(ctx, node); err != nil {
-			log.Error().Err(err).
-				Str("node", node.Redacted()).
-				Msg("failed to fetch upgrade plan")
+	schedule := w.options.Schedule.WithDefaultInterval(time.Minute)
+	schedule.Run(ctx, w.pool.BestHeight, func() {
+		if err := w.fetchUpgrade(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to fetch upgrade plan")
 		}
+	})
 
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-ticker.C:
-		}
-	}
+	return nil
 }
 
-func (w *UpgradeWatcher) OnNewBlock(ctx context.Context, node *rpc.Node, evt *ctypes.ResultEvent) error {
+// OnBlock is called by the pool once for each new block.
+func (w *UpgradeWatcher) OnBlock(ctx context.Context, node *rpc.Node, block *comettypes.Block) {
 	// Ignore is webhook is not configured
 	if w.webhook == nil {
-		return nil
+		return
 	}
 
+	// The upgrade plan is updated by the fetch loop
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	// Ignore if no upgrade plan
 	if w.nextUpgradePlan == nil {
-		return nil
-	}
-
-	// Ignore blocks if node is catching up
-	if !node.IsSynced() {
-		return nil
+		return
 	}
 
-	blockEvent := evt.Data.(comettypes.EventDataNewBlock)
-	block := blockEvent.Block
-
 	// Skip already processed blocks
 	if w.latestBlockHeight >= block.Height {
-		return nil
+		return
 	}
 
 	w.latestBlockHeight = block.Height
 
 	// Ignore if upgrade plan is for a future block
 	if block.Height < w.nextUpgradePlan.Height-1 {
-		return nil
+		return
 	}
 
 	// Ignore if webhook has already been sent
 	if w.latestWebhookSent >= w.nextUpgradePlan.Height {
-		return nil
+		return
 	}
 
 	// Upgrade plan is for this block
 	go w.triggerWebhook(ctx, node.ChainID(), *w.nextUpgradePlan)
 	w.latestWebhookSent = w.nextUpgradePlan.Height
 	w.nextUpgradePlan = nil
-
-	return nil
 }
 
 func (w *UpgradeWatcher) triggerWebhook(ctx context.Context, chainID string, plan upgrade.Plan) {
@@ -128,9 +116,8 @@ func (w *UpgradeWatcher) triggerWebhook(ctx context.Context, chainID string, pla
 	}
 }
 
-func (w *UpgradeWatcher) fetchUpgrade(ctx context.Context, node *rpc.Node) error {
-	clientCtx := (client.Context{}).WithClient(node.Client)
-	queryClient := upgrade.NewQueryClient(clientCtx)
+func (w *UpgradeWatcher) fetchUpgrade(ctx context.Context) error {
+	queryClient := upgrade.NewQueryClient(w.pool.ConnFor("upgrade"))
 
 	resp, err := queryClient.CurrentPlan(ctx, &upgrade.QueryCurrentPlanRequest{})
 	if err != nil {
@@ -142,23 +129,22 @@ func (w *UpgradeWatcher) fetchUpgrade(ctx context.Context, node *rpc.Node) error
 	if plan == nil && w.options.CheckPendingProposals {
 		switch w.options.GovModuleVersion {
 		case "v1beta1":
-			plan, err = w.checkUpgradeProposalsV1Beta1(ctx, node)
+			plan, err = w.checkUpgradeProposalsV1Beta1(ctx)
 		default: // v1
-			plan, err = w.checkUpgradeProposalsV1(ctx, node)
+			plan, err = w.checkUpgradeProposalsV1(ctx)
 		}
 		if err != nil {
 			log.Error().Err(err).Msg("failed to check upgrade proposals")
 		}
 	}
 
-	w.handleUpgradePlan(node.ChainID(), plan)
+	w.handleUpgradePlan(w.pool.ChainID, plan)
 
 	return nil
 }
 
-func (w *UpgradeWatcher) checkUpgradeProposalsV1(ctx context.Context, node *rpc.Node) (*upgrade.Plan, error) {
-	clientCtx := (client.Context{}).WithClient(node.Client)
-	queryClient := gov.NewQueryClient(clientCtx)
+func (w *UpgradeWatcher) checkUpgradeProposalsV1(ctx context.Context) (*upgrade.Plan, error) {
+	queryClient := gov.NewQueryClient(w.pool.ConnFor("upgrade"))
 
 	// Fetch all proposals in voting period
 	proposalsResp, err := queryClient.Proposals(ctx, &gov.QueryProposalsRequest{
@@ -183,9 +169,8 @@ func (w *UpgradeWatcher) checkUpgradeProposalsV1(ctx context.Context, node *rpc.
 	return nil, nil
 }
 
-func (w *UpgradeWatcher) checkUpgradeProposalsV1Beta1(ctx context.Context, node *rpc.Node) (*upgrade.Plan, error) {
-	clientCtx := (client.Context{}).WithClient(node.Client)
-	queryClient := govbeta.NewQueryClient(clientCtx)
+func (w *UpgradeWatcher) checkUpgradeProposalsV1Beta1(ctx context.Context) (*upgrade.Plan, error) {
+	queryClient := govbeta.NewQueryClient(w.pool.ConnFor("upgrade"))
 
 	// Fetch all proposals in voting period
 	proposalsResp, err := queryClient.Proposals(ctx, &govbeta.QueryProposalsRequest{
@@ -237,7 +222,9 @@ func extractUpgradePlan(content *codectypes.Any) (*upgrade.Plan, error) {
 }
 
 func (w *UpgradeWatcher) handleUpgradePlan(chainID string, plan *upgrade.Plan) {
+	w.mu.Lock()
 	w.nextUpgradePlan = plan
+	w.mu.Unlock()
 
 	if plan == nil {
 		w.metrics.UpgradePlan.Reset()
@@ -1,12 +1,12 @@
 package watcher
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sort"
 	"time"
 
-	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
 	"github.com/cosmos/cosmos-sdk/types/query"
 	staking "github.com/cosmos/cosmos-sdk/x/staking/types"
@@ -14,18 +14,28 @@ import (
 	"github.com/kilnfi/cosmos-validator-watcher/pkg/rpc"
 	"github.com/rs/zerolog/log"
 	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
 )
 
+// Number of staking validators requested per page
+const stakingValidatorsPageSize = 500
+
 type ValidatorsWatcher struct {
 	metrics    *metrics.Metrics
 	validators []TrackedValidator
 	pool       *rpc.Pool
 	opts       ValidatorsWatcherOptions
+
+	// Consensus addresses of the latest set by consensus key
+	addresses map[string]string
 }
 
 type ValidatorsWatcherOptions struct {
 	Denom         string
 	DenomExponent uint
+
+	// When to fetch the validators (default: every 30s)
+	Schedule rpc.Schedule
 }
 
 func NewValidatorsWatcher(validators []TrackedValidator, metrics *metrics.Metrics, pool *rpc.Pool, opts ValidatorsWatcherOptions) *ValidatorsWatcher {
@@ -38,44 +48,78 @@ func NewValidatorsWatcher(validators []TrackedValidator, metrics *metrics.Metric
 }
 
 func (w *ValidatorsWatcher) Start(ctx context.Context) error {
-	ticker := time.NewTicker(30 * time.Second)
-
-	for {
-		node := w.pool.GetSyncedNode()
-		if node == nil {
-			log.Warn().Msg("no node available to fetch validators")
-		} else if err := w.fetchValidators(ctx, node); err != nil {
-			log.Error().Err(err).
-				Str("node", node.Redacted()).
-				Msg("failed to fetch staking validators")
+	schedule := w.opts.Schedule.WithDefaultInterval(30 * time.Second)
+	schedule.Run(ctx, w.pool.BestHeight, func() {
+		if err := w.fetchValidators(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to fetch staking validators")
 		}
+	})
 
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-ticker.C:
-		}
-	}
+	return nil
 }
 
-func (w *ValidatorsWatcher) fetchValidators(ctx context.Context, node *rpc.Node) error {
-	clientCtx := (client.Context{}).WithClient(node.Client)
-	queryClient := staking.NewQueryClient(clientCtx)
-
-	validators, err := queryClient.Validators(ctx, &staking.QueryValidatorsRequest{
-		Pagination: &query.PageRequest{
-			Limit: 3000,
-		},
+func (w *ValidatorsWatcher) fetchValidators(ctx context.Context) error {
+	// All the pages are fetched from the same node
+	var validators []staking.Validator
+	err := w.pool.WithNodeConn(ctx, "validators", func(conn grpc.ClientConnInterface) (err error) {
+		validators, err = FetchStakingValidators(ctx, conn)
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to get validators: %w", err)
 	}
 
-	w.handleValidators(node.ChainID(), validators.Validators)
+	w.metrics.ValidatorSet.WithLabelValues(w.pool.ChainID, "staking").Set(float64(len(validators)))
+	w.handleValidators(w.pool.ChainID, validators)
 
 	return nil
 }
 
+// FetchStakingValidators returns all the staking validators, following the
+// pagination keys until the last page. The connection must send all the
+// queries to the same node (see rpc.Pool.WithNodeConn).
+func FetchStakingValidators(ctx context.Context, conn grpc.ClientConnInterface) ([]staking.Validator, error) {
+	queryClient := staking.NewQueryClient(conn)
+
+	var (
+		validators []staking.Validator
+		total      uint64
+		key        []byte
+	)
+
+	for {
+		resp, err := queryClient.Validators(ctx, &staking.QueryValidatorsRequest{
+			Pagination: &query.PageRequest{
+				Key:        key,
+				Limit:      stakingValidatorsPageSize,
+				CountTotal: key == nil,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if key == nil && resp.Pagination != nil {
+			total = resp.Pagination.Total
+			validators = make([]staking.Validator, 0, total)
+		}
+		validators = append(validators, resp.Validators...)
+
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			break
+		}
+		if bytes.Equal(resp.Pagination.NextKey, key) {
+			return nil, fmt.Errorf("pagination key %X returned twice", key)
+		}
+		key = resp.Pagination.NextKey
+	}
+
+	if total > 0 && uint64(len(validators)) != total {
+		log.Warn().Msgf("fetched %d staking validators out of %d", len(validators), total)
+	}
+
+	return validators, nil
+}
+
 func (w *ValidatorsWatcher) handleValidators(chainID string, validators []staking.Validator) {
 	// Sort validators by tokens & status (bonded, unbonded, jailed)
 	sort.Sort(RankedValidators(validators))
@@ -91,32 +135,56 @@ func (w *ValidatorsWatcher) handleValidators(chainID string, validators []stakin
 		if val.Status == staking.Bonded && (seatPrice.IsZero() || seatPrice.GreaterThan(tokens)) {
 			seatPrice = tokens
 		}
-		w.metrics.SeatPrice.WithLabelValues(chainID, w.opts.Denom).Set(seatPrice.InexactFloat64())
 	}
+	w.metrics.SeatPrice.WithLabelValues(chainID, w.opts.Denom).Set(seatPrice.InexactFloat64())
+
+	ranks := w.indexValidators(validators)
 
 	for _, tracked := range w.validators {
-		name := tracked.Name
+		i, ok := ranks[tracked.Address]
+		if !ok {
+			continue
+		}
 
-		for i, val := range validators {
+		var (
+			val      = validators[i]
+			name     = tracked.Name
+			address  = tracked.Address
+			rank     = i + 1
+			isBonded = val.Status == staking.Bonded
+			isJailed = val.Jailed
+			tokens   = decimal.NewFromBigInt(val.Tokens.BigInt(), -int32(denomExponent))
+		)
+
+		w.metrics.Rank.WithLabelValues(chainID, address, name).Set(float64(rank))
+		w.metrics.Tokens.WithLabelValues(chainID, address, name, w.opts.Denom).Set(tokens.InexactFloat64())
+		w.metrics.IsBonded.WithLabelValues(chainID, address, name).Set(metrics.BoolToFloat64(isBonded))
+		w.metrics.IsJailed.WithLabelValues(chainID, address, name).Set(metrics.BoolToFloat64(isJailed))
+	}
+}
+
+// indexValidators returns the position of each validator in the list by
+// consensus address. Addresses are only computed for consensus keys which
+// weren't part of the previous set.
+func (w *ValidatorsWatcher) indexValidators(validators []staking.Validator) map[string]int {
+	addresses := make(map[string]string, len(validators))
+	ranks := make(map[string]int, len(validators))
+
+	for i, val := range validators {
+		key := string(val.ConsensusPubkey.Value)
+		address, ok := w.addresses[key]
+		if !ok {
 			pubkey := ed25519.PubKey{Key: val.ConsensusPubkey.Value[2:]}
-			address := pubkey.Address().String()
-
-			if tracked.Address == address {
-				var (
-					rank     = i + 1
-					isBonded = val.Status == staking.Bonded
-					isJailed = val.Jailed
-					tokens   = decimal.NewFromBigInt(val.Tokens.BigInt(), -int32(denomExponent))
-				)
-
-				w.metrics.Rank.WithLabelValues(chainID, address, name).Set(float64(rank))
-				w.metrics.Tokens.WithLabelValues(chainID, address, name, w.opts.Denom).Set(tokens.InexactFloat64())
-				w.metrics.IsBonded.WithLabelValues(chainID, address, name).Set(metrics.BoolToFloat64(isBonded))
-				w.metrics.IsJailed.WithLabelValues(chainID, address, name).Set(metrics.BoolToFloat64(isJailed))
-				break
-			}
+			address = pubkey.Address().String()
+		}
+		addresses[key] = address
+		if _, ok := ranks[address]; !ok {
+			ranks[address] = i
 		}
 	}
+	w.addresses = addresses
+
+	return ranks
 }
 
 type RankedValidators []staking.Validator
@@ -1,15 +1,21 @@
 package watcher
 
 import (
+	"context"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"testing"
 
 	"cosmossdk.io/math"
+	"github.com/cometbft/cometbft/crypto/ed25519"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
 	staking "github.com/cosmos/cosmos-sdk/x/staking/types"
 	"github.com/kilnfi/cosmos-validator-watcher/pkg/metrics"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"gotest.tools/assert"
 )
 
@@ -86,3 +92,96 @@ func TestValidatorsWatcher(t *testing.T) {
 		assert.Equal(t, float64(0), testutil.ToFloat64(validatorsWatcher.metrics.IsJailed.WithLabelValues(chainID, kilnAddress, kilnName)))
 	})
 }
+
+func BenchmarkHandleValidators(b *testing.B) {
+	const (
+		setSize = 1000
+		tracked = 100
+	)
+
+	validators := make([]staking.Validator, 0, setSize)
+	addresses := make([]string, 0, setSize)
+	for i := 0; i < setSize; i++ {
+		pubkey := ed25519.GenPrivKeyFromSecret([]byte{byte(i), byte(i >> 8)}).PubKey()
+		validators = append(validators, staking.Validator{
+			ConsensusPubkey: &codectypes.Any{
+				TypeUrl: "/cosmos.crypto.ed25519.PubKey",
+				Value:   append([]byte{0x0a, 0x20}, pubkey.Bytes()...),
+			},
+			Status: staking.Bonded,
+			Tokens: math.NewInt(int64(setSize - i)),
+		})
+		addresses = append(addresses, pubkey.Address().String())
+	}
+
+	// Track the validators at the end of the set
+	trackedValidators := make([]TrackedValidator, 0, tracked)
+	for _, address := range addresses[setSize-tracked:] {
+		trackedValidators = append(trackedValidators, TrackedValidator{Address: address})
+	}
+
+	validatorsWatcher := NewValidatorsWatcher(
+		trackedValidators,
+		metrics.New("cosmos_validator_watcher"),
+		nil,
+		ValidatorsWatcherOptions{Denom: "denom", DenomExponent: 6},
+	)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		validatorsWatcher.handleValidators("chain-42", validators)
+	}
+}
+
+// pagedValidatorsConn serves the staking validators over several pages.
+type pagedValidatorsConn struct {
+	validators []staking.Validator
+	pageSize   int
+	requests   []*query.PageRequest
+}
+
+func (c *pagedValidatorsConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	req := args.(*staking.QueryValidatorsRequest)
+	c.requests = append(c.requests, req.Pagination)
+
+	start := 0
+	if len(req.Pagination.Key) > 0 {
+		start = int(req.Pagination.Key[0])
+	}
+	end := min(start+c.pageSize, len(c.validators))
+
+	resp := reply.(*staking.QueryValidatorsResponse)
+	resp.Validators = c.validators[start:end]
+	resp.Pagination = &query.PageResponse{}
+	if end < len(c.validators) {
+		resp.Pagination.NextKey = []byte{byte(end)}
+	}
+	if req.Pagination.CountTotal {
+		resp.Pagination.Total = uint64(len(c.validators))
+	}
+	return nil
+}
+
+func (c *pagedValidatorsConn) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestFetchStakingValidators(t *testing.T) {
+	validators := make([]staking.Validator, 0, 25)
+	for i := 0; i < 25; i++ {
+		validators = append(validators, staking.Validator{OperatorAddress: fmt.Sprintf("valoper%d", i)})
+	}
+
+	// The node returns less validators per page than requested
+	conn := &pagedValidatorsConn{validators: validators, pageSize: 10}
+
+	fetched, err := FetchStakingValidators(context.Background(), conn)
+	assert.NilError(t, err)
+	assert.Equal(t, 25, len(fetched))
+	assert.Equal(t, "valoper24", fetched[24].OperatorAddress)
+
+	assert.Equal(t, 3, len(conn.requests))
+	assert.Equal(t, true, conn.requests[0].CountTotal)
+	assert.Equal(t, 0, len(conn.requests[0].Key))
+	assert.DeepEqual(t, []byte{20}, conn.requests[2].Key)
+}
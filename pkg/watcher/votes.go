@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/cosmos/cosmos-sdk/client"
 	gov "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
 	govbeta "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
 	"github.com/kilnfi/cosmos-validator-watcher/pkg/metrics"
@@ -24,6 +23,9 @@ type VotesWatcher struct {
 
 type VotesWatcherOptions struct {
 	GovModuleVersion string
+
+	// When to fetch the proposals & votes (default: every minute)
+	Schedule rpc.Schedule
 }
 
 func NewVotesWatcher(validators []TrackedValidator, metrics *metrics.Metrics, pool *rpc.Pool, options VotesWatcherOptions) *VotesWatcher {
@@ -36,27 +38,17 @@ func NewVotesWatcher(validators []TrackedValidator, metrics *metrics.Metrics, po
 }
 
 func (w *VotesWatcher) Start(ctx context.Context) error {
-	ticker := time.NewTicker(1 * time.Minute)
-
-	for {
-		node := w.pool.GetSyncedNode()
-		if node == nil {
-			log.Warn().Msg("no node available to fetch proposals")
-		} else if err := w.fetchProposals(ctx, node); err != nil {
-			log.Error().Err(err).
-				Str("node", node.Redacted()).
-				Msg("failed to fetch pending proposals")
+	schedule := w.options.Schedule.WithDefaultInterval(time.Minute)
+	schedule.Run(ctx, w.pool.BestHeight, func() {
+		if err := w.fetchProposals(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to fetch pending proposals")
 		}
+	})
 
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-ticker.C:
-		}
-	}
+	return nil
 }
 
-func (w *VotesWatcher) fetchProposals(ctx context.Context, node *rpc.Node) error {
+func (w *VotesWatcher) fetchProposals(ctx context.Context) error {
 	var (
 		votes map[uint64]map[TrackedValidator]bool
 		err   error
@@ -64,9 +56,9 @@ func (w *VotesWatcher) fetchProposals(ctx context.Context, node *rpc.Node) error
 
 	switch w.options.GovModuleVersion {
 	case "v1beta1":
-		votes, err = w.fetchProposalsV1Beta1(ctx, node)
+		votes, err = w.fetchProposalsV1Beta1(ctx)
 	default: // v1
-		votes, err = w.fetchProposalsV1(ctx, node)
+		votes, err = w.fetchProposalsV1(ctx)
 	}
 
 	if err != nil {
@@ -77,7 +69,7 @@ func (w *VotesWatcher) fetchProposals(ctx context.Context, node *rpc.Node) error
 	for proposalId, votes := range votes {
 		for validator, voted := range votes {
 			w.metrics.Vote.
-				WithLabelValues(node.ChainID(), validator.Address, validator.Name, fmt.Sprintf("%d", proposalId)).
+				WithLabelValues(w.pool.ChainID, validator.Address, validator.Name, fmt.Sprintf("%d", proposalId)).
 				Set(metrics.BoolToFloat64(voted))
 		}
 	}
@@ -85,11 +77,10 @@ func (w *VotesWatcher) fetchProposals(ctx context.Context, node *rpc.Node) error
 	return nil
 }
 
-func (w *VotesWatcher) fetchProposalsV1(ctx context.Context, node *rpc.Node) (map[uint64]map[TrackedValidator]bool, error) {
+func (w *VotesWatcher) fetchProposalsV1(ctx context.Context) (map[uint64]map[TrackedValidator]bool, error) {
 	votes := make(map[uint64]map[TrackedValidator]bool)
 
-	clientCtx := (client.Context{}).WithClient(node.Client)
-	queryClient := gov.NewQueryClient(clientCtx)
+	queryClient := gov.NewQueryClient(w.pool.ConnFor("votes"))
 
 	// Fetch all proposals in voting period
 	proposalsResp, err := queryClient.Proposals(ctx, &gov.QueryProposalsRequest{
@@ -99,7 +90,7 @@ func (w *VotesWatcher) fetchProposalsV1(ctx context.Context, node *rpc.Node) (ma
 		return votes, fmt.Errorf("failed to fetch proposals in voting period: %w", err)
 	}
 
-	chainID := node.ChainID()
+	chainID := w.pool.ChainID
 
 	// For each proposal, fetch validators vote
 	for _, proposal := range proposalsResp.GetProposals() {
@@ -142,11 +133,10 @@ func (w *VotesWatcher) fetchProposalsV1(ctx context.Context, node *rpc.Node) (ma
 	return votes, nil
 }
 
-func (w *VotesWatcher) fetchProposalsV1Beta1(ctx context.Context, node *rpc.Node) (map[uint64]map[TrackedValidator]bool, error) {
+func (w *VotesWatcher) fetchProposalsV1Beta1(ctx context.Context) (map[uint64]map[TrackedValidator]bool, error) {
 	votes := make(map[uint64]map[TrackedValidator]bool)
 
-	clientCtx := (client.Context{}).WithClient(node.Client)
-	queryClient := govbeta.NewQueryClient(clientCtx)
+	queryClient := govbeta.NewQueryClient(w.pool.ConnFor("votes"))
 
 	// Fetch all proposals in voting period
 	proposalsResp, err := queryClient.Proposals(ctx, &govbeta.QueryProposalsRequest{
@@ -156,7 +146,7 @@ func (w *VotesWatcher) fetchProposalsV1Beta1(ctx context.Context, node *rpc.Node
 		return votes, fmt.Errorf("failed to fetch proposals in voting period: %w", err)
 	}
 
-	chainID := node.ChainID()
+	chainID := w.pool.ChainID
 
 	// For each proposal, fetch validators vote
 	for _, proposal := range proposalsResp.GetProposals() {